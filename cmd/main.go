@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/cli"
@@ -11,7 +10,7 @@ func main() {
 	app := cli.NewCLI()
 
 	if err := app.Execute(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		cli.PrintError(os.Stderr, err)
+		os.Exit(cli.ExitCode(err))
 	}
 }
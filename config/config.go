@@ -3,12 +3,21 @@ package config
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
+	Backend     string
+	SQLitePath  string
+	AutoMigrate bool
+	// Actor is recorded as the author of every change, in the todo history.
+	Actor            string
 	PostgresHost     string
 	PostgresPort     string
 	PostgresUser     string
@@ -25,6 +34,10 @@ func LoadConfig() *Config {
 	}
 
 	config := &Config{
+		Backend:          getEnv("TODO_BACKEND", "postgres"),
+		SQLitePath:       getEnv("TODO_SQLITE_PATH", "todo.db"),
+		AutoMigrate:      getEnvBool("TODO_AUTO_MIGRATE", true),
+		Actor:            getEnv("TODO_ACTOR", getEnv("USER", "unknown")),
 		PostgresHost:     getEnv("POSTGRES_HOST", "localhost"),
 		PostgresPort:     getEnv("POSTGRES_PORT", "5432"),
 		PostgresUser:     getEnv("POSTGRES_USER", "todo_user"),
@@ -47,6 +60,38 @@ func (c *Config) GetPostgresDSN() string {
 	)
 }
 
+// GetBackendURL resolves TODO_BACKEND into a storage URL. Besides full URLs
+// (postgres://, sqlite://, file:, memory://) it accepts the shorthands
+// "postgres", "sqlite" and "memory", which are completed from the remaining
+// settings.
+func (c *Config) GetBackendURL() string {
+	switch c.Backend {
+	case "postgres", "postgresql":
+		return c.GetPostgresURL()
+	case "sqlite":
+		return "sqlite://" + c.SQLitePath
+	case "memory":
+		return "memory://"
+	}
+
+	if !strings.Contains(c.Backend, ":") {
+		// Leave unknown shorthands for the registry to reject by name
+		return c.Backend + "://"
+	}
+	return c.Backend
+}
+
+func (c *Config) GetPostgresURL() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.PostgresUser, c.PostgresPassword),
+		Host:     net.JoinHostPort(c.PostgresHost, c.PostgresPort),
+		Path:     "/" + c.PostgresDB,
+		RawQuery: url.Values{"sslmode": {c.PostgresSSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -54,3 +99,11 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) archiveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive [id]",
+		Short: "Hide a TODO, or every completed TODO, from the default listing",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			completed, _ := cmd.Flags().GetBool("completed")
+			switch {
+			case completed && len(args) > 0:
+				return flagError("completed", errors.New("cannot be combined with a todo id"))
+			case !completed && len(args) == 0:
+				return &usageError{err: errors.New("give a todo id or --completed")}
+			case !completed && cmd.Flags().Changed("older-than"):
+				return flagError("older-than", errors.New("only valid with --completed"))
+			}
+
+			ctx := cli.context()
+			if completed {
+				before, err := parseAgeFlag(cmd, "older-than")
+				if err != nil {
+					return flagError("older-than", err)
+				}
+				if before == nil {
+					now := time.Now()
+					before = &now
+				}
+
+				archived, err := cli.todoService.ArchiveCompleted(ctx, *before)
+				if err != nil {
+					return err
+				}
+
+				return cli.emitValue(struct {
+					Archived int `json:"archived"`
+				}{archived}, func() {
+					fmt.Printf("Archived %d todo(s)\n", archived)
+				})
+			}
+
+			id, err := cli.resolveID(args[0])
+			if err != nil {
+				return err
+			}
+
+			todo, err := cli.todoService.ArchiveTodo(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Todo archived. Undo with: todo unarchive %s\n", id.String()[:8])
+			})
+		},
+	}
+
+	cmd.Flags().Bool("completed", false, "Archive every completed todo instead of a single one")
+	cmd.Flags().String("older-than", "", "Only archive todos completed at least this long ago, e.g. 14d or 2w")
+
+	return cmd
+}
+
+func (cli *CLI) unarchiveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unarchive [id]",
+		Short: "Show an archived TODO in the default listing again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := cli.resolveID(args[0])
+			if err != nil {
+				return err
+			}
+
+			todo, err := cli.todoService.UnarchiveTodo(cli.context(), id)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Todo unarchived successfully!\n")
+				cli.printTodo(todo)
+			})
+		},
+	}
+}
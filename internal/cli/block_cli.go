@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) blockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "block [id] --on [id]",
+		Short: "Mark a todo as blocked by another todo",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, blockerID, err := cli.blockArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			todo, err := cli.todoService.BlockTodo(cli.context(), id, blockerID)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Todo is now blocked by %s!\n", blockerID.String()[:8])
+				cli.printTodo(todo)
+			})
+		},
+	}
+
+	cmd.Flags().String("on", "", "ID of the todo that has to be done first")
+	cmd.MarkFlagRequired("on")
+
+	return cmd
+}
+
+func (cli *CLI) unblockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unblock [id] --on [id]",
+		Short: "Remove a dependency between two todos",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, blockerID, err := cli.blockArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			todo, err := cli.todoService.UnblockTodo(cli.context(), id, blockerID)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Todo is no longer blocked by %s!\n", blockerID.String()[:8])
+				cli.printTodo(todo)
+			})
+		},
+	}
+
+	cmd.Flags().String("on", "", "ID of the todo it no longer waits on")
+	cmd.MarkFlagRequired("on")
+
+	return cmd
+}
+
+func (cli *CLI) blockArgs(cmd *cobra.Command, args []string) (uuid.UUID, uuid.UUID, error) {
+	id, err := cli.resolveID(args[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	on, _ := cmd.Flags().GetString("on")
+	blockerID, err := cli.resolveID(on)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return id, blockerID, nil
+}
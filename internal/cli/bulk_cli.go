@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// bulkFilterFlags are the flags addBulkFlags adds that select todos instead
+// of IDs.
+var bulkFilterFlags = []string{"tag", "project", "search", "completed", "pending", "overdue"}
+
+// addBulkFlags lets a command that takes todo IDs select its todos with a
+// filter instead, and preview the change with --dry-run.
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("tag", nil, "Apply to every todo with this tag instead of to IDs (repeatable, all must match)")
+	cmd.Flags().String("project", "", "Apply to every todo in this project instead of to IDs")
+	cmd.Flags().StringP("search", "s", "", "Apply to every todo whose title or description contains this text instead of to IDs")
+	cmd.Flags().Bool("completed", false, "Apply to every completed todo instead of to IDs")
+	cmd.Flags().Bool("pending", false, "Apply to every pending todo instead of to IDs")
+	cmd.Flags().Bool("overdue", false, "Apply to every pending todo past its due date instead of to IDs")
+	cmd.Flags().Bool("dry-run", false, "Show the todos that would change without changing them")
+	cmd.MarkFlagsMutuallyExclusive("completed", "pending")
+	cmd.MarkFlagsMutuallyExclusive("completed", "overdue")
+}
+
+// bulkRequest builds the request for action on the todos refs names, or on
+// every todo the filter flags match. single is set when the command was
+// given one ID and nothing else, which it can handle the way it always has.
+func (cli *CLI) bulkRequest(cmd *cobra.Command, action domain.BulkAction, refs []string) (request domain.BulkRequest, single bool, err error) {
+	filtered := slices.ContainsFunc(bulkFilterFlags, cmd.Flags().Changed)
+	request.Action = action
+	request.DryRun, _ = cmd.Flags().GetBool("dry-run")
+
+	switch {
+	case filtered && len(refs) > 0:
+		return request, false, &usageError{err: errors.New("give todo ids or filters, not both")}
+	case !filtered && len(refs) == 0:
+		return request, false, &usageError{err: errors.New("give at least one todo id or a filter such as --tag")}
+	case filtered:
+		request.Query, err = cli.bulkQuery(cmd)
+		return request, false, err
+	}
+
+	for _, ref := range refs {
+		id, err := cli.resolveID(ref)
+		if err != nil {
+			return request, false, err
+		}
+		if !slices.Contains(request.IDs, id) {
+			request.IDs = append(request.IDs, id)
+		}
+	}
+
+	return request, len(refs) == 1 && !request.DryRun, nil
+}
+
+// bulkQuery translates the filter flags addBulkFlags adds into a query, the
+// way list does.
+func (cli *CLI) bulkQuery(cmd *cobra.Command) (domain.TodoQuery, error) {
+	tags, _ := cmd.Flags().GetStringArray("tag")
+	search, _ := cmd.Flags().GetString("search")
+	completed, _ := cmd.Flags().GetBool("completed")
+	pending, _ := cmd.Flags().GetBool("pending")
+	overdue, _ := cmd.Flags().GetBool("overdue")
+
+	projectID, err := cli.projectFlag(cmd, true)
+	if err != nil {
+		return domain.TodoQuery{}, err
+	}
+
+	query := domain.TodoQuery{Tags: tags, Text: search, ProjectID: projectID}
+	if overdue {
+		now := time.Now()
+		query.DueBefore = &now
+		pending = true
+	}
+	if completed || pending {
+		query.Completed = &completed
+	}
+
+	return query, nil
+}
+
+// runBulk carries out request and reports the todos it changed, or with
+// --dry-run would have changed.
+func (cli *CLI) runBulk(request domain.BulkRequest) error {
+	result, err := cli.todoService.BulkUpdate(cli.context(), request)
+	if err != nil {
+		return err
+	}
+
+	return cli.emitValue(result, func() {
+		switch {
+		case len(result.Changed) == 0:
+			fmt.Printf("Nothing to change in the %d matching todo(s)\n", result.Matched)
+			return
+		case result.DryRun:
+			fmt.Printf("Dry run: %s would change %d of %d matching todo(s)\n", result.Action, len(result.Changed), result.Matched)
+		default:
+			fmt.Printf("Changed %d of %d matching todo(s)\n", len(result.Changed), result.Matched)
+		}
+		cli.printTodoTable(result.Changed, nil)
+	})
+}
+
+func (cli *CLI) moveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move [id]...",
+		Short: "Move TODOs to another project, or out of their project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request, _, err := cli.bulkRequest(cmd, domain.BulkMove, args)
+			if err != nil {
+				return err
+			}
+			// Moving into an archived project would hide the todos in it
+			if request.ProjectID, err = cli.projectFlagNamed(cmd, "to", false); err != nil {
+				return err
+			}
+
+			return cli.runBulk(request)
+		},
+	}
+
+	cmd.Flags().String("to", "", "Project to move the todos to")
+	cmd.Flags().Bool("no-project", false, "Take the todos out of their project")
+	cmd.MarkFlagsMutuallyExclusive("to", "no-project")
+	cmd.MarkFlagsOneRequired("to", "no-project")
+	addBulkFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+// writeConflict explains a rejected update as a diff between the todo as it
+// is now stored and the values the request tried to write. Fields the request
+// leaves alone, or that already hold the requested value, are skipped.
+func writeConflict(w io.Writer, current *domain.Todo, request domain.UpdateTodoRequest, projectName func(*uuid.UUID) string) {
+	type change struct{ field, stored, yours string }
+	var changes []change
+	add := func(field, stored, yours string) {
+		if stored != yours {
+			changes = append(changes, change{field, stored, yours})
+		}
+	}
+
+	if request.Title != nil {
+		add("title", fmt.Sprintf("%q", current.Title), fmt.Sprintf("%q", *request.Title))
+	}
+	if request.ClearDescription || request.Description != nil || request.AppendDescription != nil {
+		description := current.Description
+		if request.ClearDescription {
+			description = ""
+		} else {
+			if request.Description != nil {
+				description = *request.Description
+			}
+			if request.AppendDescription != nil {
+				if extra := strings.TrimSpace(*request.AppendDescription); extra != "" {
+					description = strings.TrimLeft(description+"\n"+extra, "\n")
+				}
+			}
+		}
+		add("description", fmt.Sprintf("%q", current.Description), fmt.Sprintf("%q", description))
+	}
+	if request.Priority != nil {
+		add("priority", current.Priority.String(), request.Priority.String())
+	}
+	if request.ClearDueDate {
+		add("due", formatConflictTime(current.DueDate), "-")
+	} else if request.DueDate != nil {
+		add("due", formatConflictTime(current.DueDate), formatConflictTime(request.DueDate))
+	}
+	if request.ClearProject {
+		add("project", projectName(current.ProjectID), "-")
+	} else if request.ProjectID != nil {
+		add("project", projectName(current.ProjectID), projectName(request.ProjectID))
+	}
+	if request.ClearParent {
+		add("parent", formatConflictID(current.ParentID), "-")
+	} else if request.ParentID != nil {
+		add("parent", formatConflictID(current.ParentID), formatConflictID(request.ParentID))
+	}
+	if request.ClearRecurrence {
+		add("repeat", formatRecurrence(current.Recurrence), "-")
+	} else if request.Recurrence != nil {
+		add("repeat", formatRecurrence(current.Recurrence), *request.Recurrence)
+	}
+
+	fmt.Fprintf(w, "Todo %s was changed by someone else, nothing was saved.\n", current.ID.String()[:8])
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "It already holds the values you asked for.\n")
+		return
+	}
+
+	fmt.Fprintf(w, "--- stored (version %d)\n+++ yours\n", current.Version)
+	for _, c := range changes {
+		fmt.Fprintf(w, "-%s: %s\n+%s: %s\n", c.field, c.stored, c.field, c.yours)
+	}
+}
+
+func formatConflictID(id *uuid.UUID) string {
+	if id == nil {
+		return "-"
+	}
+	return id.String()[:8]
+}
+
+func formatConflictTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteConflict(t *testing.T) {
+	current := &domain.Todo{
+		ID:          uuid.MustParse("6f1c2a7e-3b7d-4c55-9d8e-1f2a3b4c5d6e"),
+		Title:       "Ship it",
+		Description: "Notes",
+		Priority:    domain.PriorityHigh,
+		Version:     4,
+	}
+	title, extra := "Ship release", "More notes"
+	priority := domain.PriorityHigh
+	noProject := func(*uuid.UUID) string { return "-" }
+
+	// Fields already holding the requested value are left out
+	var out bytes.Buffer
+	writeConflict(&out, current, domain.UpdateTodoRequest{
+		Title:             &title,
+		AppendDescription: &extra,
+		Priority:          &priority,
+	}, noProject)
+	assert.Equal(t, `Todo 6f1c2a7e was changed by someone else, nothing was saved.
+--- stored (version 4)
++++ yours
+-title: "Ship it"
++title: "Ship release"
+-description: "Notes"
++description: "Notes\nMore notes"
+`, out.String())
+
+	out.Reset()
+	writeConflict(&out, current, domain.UpdateTodoRequest{Priority: &priority}, noProject)
+	assert.Equal(t, `Todo 6f1c2a7e was changed by someone else, nothing was saved.
+It already holds the values you asked for.
+`, out.String())
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+// Exit codes of the todo binary, so scripts can react to failures without
+// parsing messages.
+const (
+	ExitOK       = 0
+	ExitError    = 1 // unexpected failure, e.g. the database is unreachable
+	ExitUsage    = 2 // invalid arguments, flags or input
+	ExitNotFound = 3 // the todo or project does not exist
+	ExitConflict = 4 // the change clashes with stored data, e.g. a blocked todo or a duplicate name
+)
+
+// ExitCode returns the exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	var usage *usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage), errors.Is(err, domain.ErrValidation):
+		return ExitUsage
+	case errors.Is(err, domain.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return ExitConflict
+	default:
+		return ExitError
+	}
+}
+
+// PrintError writes err for people, listing each invalid field of a
+// validation error on its own line.
+func PrintError(w io.Writer, err error) {
+	var invalid *domain.ValidationError
+	if !errors.As(err, &invalid) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "Error: %v\n", domain.ErrValidation)
+	for _, field := range invalid.Fields {
+		fmt.Fprintf(w, "  %s: %s\n", field.Field, field.Message)
+	}
+}
+
+// usageError is a command line that could not be understood, such as an
+// unknown flag or a missing argument.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// flagError reports an unusable value for the --name flag.
+func flagError(name string, err error) error {
+	return &usageError{err: fmt.Errorf("invalid --%s: %w", name, err)}
+}
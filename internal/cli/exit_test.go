@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitOK},
+		{errors.New("connection refused"), ExitError},
+		{flagError("due", errors.New("unrecognised date")), ExitUsage},
+		{fmt.Errorf("%w: tag cannot contain spaces", domain.ErrValidation), ExitUsage},
+		{&domain.AmbiguousIDError{Prefix: "ab"}, ExitUsage},
+		{fmt.Errorf("parent: %w", fmt.Errorf("todo ab12: %w", domain.ErrNotFound)), ExitNotFound},
+		{fmt.Errorf("%w: todo is blocked", domain.ErrConflict), ExitConflict},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ExitCode(tt.err), "%v", tt.err)
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	var out bytes.Buffer
+	PrintError(&out, &domain.ValidationError{Fields: []domain.FieldError{
+		{Field: "title", Message: "is required"},
+		{Field: "tags", Message: `"a b" cannot contain spaces or commas`},
+	}})
+	assert.Equal(t, `Error: invalid input
+  title: is required
+  tags: "a b" cannot contain spaces or commas
+`, out.String())
+
+	out.Reset()
+	PrintError(&out, fmt.Errorf("todo ab12: %w", domain.ErrNotFound))
+	assert.Equal(t, "Error: todo ab12: not found\n", out.String())
+}
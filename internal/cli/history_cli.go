@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) historyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history [id]",
+		Short: "Show who changed a TODO, when, and what they changed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cli.context()
+
+			// Deleted todos keep their history until the trash is emptied
+			id, err := cli.resolveID(args[0])
+			if errors.Is(err, domain.ErrNotFound) {
+				id, err = cli.todoService.ResolveTrashedTodoID(ctx, args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			events, err := cli.todoService.FindHistory(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitValue(events, func() {
+				writeHistory(os.Stdout, events, cli.projectName)
+			})
+		},
+	}
+}
+
+// writeHistory renders events as a timeline, each followed by the fields it
+// changed.
+func writeHistory(w io.Writer, events []*domain.TodoEvent, projectName func(*uuid.UUID) string) {
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No history recorded")
+		return
+	}
+
+	for _, event := range events {
+		var undone string
+		if event.UndoneAt != nil {
+			undone = " (undone)"
+		}
+		fmt.Fprintf(w, "%s  %s  %s%s\n", event.OccurredAt.Local().Format("2006-01-02 15:04:05"), event.Actor, event.Action, undone)
+		writeChanges(w, "    ", event, projectName)
+	}
+}
+
+// writeChanges lists the fields event changed, one per line.
+func writeChanges(w io.Writer, indent string, event *domain.TodoEvent, projectName func(*uuid.UUID) string) {
+	for _, change := range event.Changes {
+		to := formatEventValue(change.Field, change.New, projectName)
+		if event.Action == domain.EventCreated {
+			fmt.Fprintf(w, "%s%s: %s\n", indent, change.Field, to)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s: %s → %s\n", indent, change.Field, formatEventValue(change.Field, change.Old, projectName), to)
+	}
+}
+
+// formatEventValue renders a value as TodoChanges stores it the way the rest
+// of the CLI shows that field.
+func formatEventValue(field string, value *string, projectName func(*uuid.UUID) string) string {
+	if field == "completed" || field == "archived" {
+		if value == nil {
+			return "no"
+		}
+		return "yes"
+	}
+	if value == nil {
+		return "-"
+	}
+
+	switch field {
+	case "title", "description":
+		return fmt.Sprintf("%q", *value)
+	case "due_date":
+		if due, err := time.Parse(time.RFC3339, *value); err == nil {
+			return due.Local().Format("2006-01-02 15:04")
+		}
+	case "project_id", "parent_id":
+		id, err := uuid.Parse(*value)
+		if err != nil {
+			break
+		}
+		if field == "project_id" {
+			return projectName(&id)
+		}
+		return id.String()[:8]
+	case "recurrence":
+		return formatRecurrence(*value)
+	case "blocked_by":
+		ids := strings.Split(*value, ",")
+		for i, id := range ids {
+			ids[i] = id[:min(len(id), 8)]
+		}
+		return strings.Join(ids, ", ")
+	}
+	return *value
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHistory(t *testing.T) {
+	due := time.Date(2025, 3, 14, 17, 0, 0, 0, time.Local)
+	created := &domain.Todo{ID: uuid.New(), Title: "Ship", Priority: domain.PriorityHigh}
+	updated := *created
+	updated.Title, updated.DueDate, updated.Priority = "Ship release", &due, domain.PriorityNone
+	done := updated
+	done.Completed = true
+
+	at := time.Date(2025, 3, 12, 10, 30, 0, 0, time.Local)
+	events := []*domain.TodoEvent{
+		{Action: domain.EventCreated, Changes: domain.TodoChanges(nil, created), Actor: "ana", OccurredAt: at},
+		{Action: domain.EventUpdated, Changes: domain.TodoChanges(created, &updated), Actor: "ben", OccurredAt: at.Add(time.Hour)},
+		{Action: domain.EventCompleted, Changes: domain.TodoChanges(&updated, &done), Actor: "ana", OccurredAt: at.Add(2 * time.Hour)},
+		{Action: domain.EventDeleted, Changes: []domain.FieldChange{}, Actor: "ana", OccurredAt: at.Add(3 * time.Hour)},
+	}
+
+	var out bytes.Buffer
+	writeHistory(&out, events, func(*uuid.UUID) string { return "-" })
+	assert.Equal(t, `2025-03-12 10:30:00  ana  created
+    title: "Ship"
+    priority: high
+2025-03-12 11:30:00  ben  updated
+    title: "Ship" → "Ship release"
+    priority: high → -
+    due_date: - → 2025-03-14 17:00
+2025-03-12 12:30:00  ana  completed
+    completed: no → yes
+2025-03-12 13:30:00  ana  deleted
+`, out.String())
+
+	out.Reset()
+	writeHistory(&out, nil, nil)
+	assert.Equal(t, "No history recorded\n", out.String())
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) migrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Leave the schema exactly as it is until the subcommand runs
+			return cli.connect(cmd, false)
+		},
+	}
+
+	cmd.AddCommand(
+		cli.migrateUpCommand(),
+		cli.migrateDownCommand(),
+		cli.migrateStatusCommand(),
+	)
+
+	return cmd
+}
+
+func (cli *CLI) migrateUpCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applied, err := cli.backend.Migrator.Up(context.Background())
+			for _, m := range applied {
+				fmt.Printf("⬆️  Applied %03d_%s\n", m.Version, m.Name)
+			}
+			if err != nil {
+				return err
+			}
+
+			if len(applied) == 0 {
+				fmt.Println("Database schema is up to date")
+			}
+
+			return nil
+		},
+	}
+}
+
+func (cli *CLI) migrateDownCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the most recently applied migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps, _ := cmd.Flags().GetInt("steps")
+
+			reverted, err := cli.backend.Migrator.Down(context.Background(), steps)
+			for _, m := range reverted {
+				fmt.Printf("⬇️  Reverted %03d_%s\n", m.Version, m.Name)
+			}
+			if err != nil {
+				return err
+			}
+
+			if len(reverted) == 0 {
+				fmt.Println("No migrations to revert")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntP("steps", "n", 1, "Number of migrations to revert")
+
+	return cmd
+}
+
+func (cli *CLI) migrateStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show which migrations have been applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := cli.backend.Migrator.Status(context.Background())
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+			for _, s := range statuses {
+				status, appliedAt := "⏳ Pending", "-"
+				if s.Applied {
+					status = "✅ Applied"
+					appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+			}
+			w.Flush()
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	outputTable    outputFormat = "table"
+	outputJSON     outputFormat = "json"
+	outputJSONL    outputFormat = "jsonl"
+	outputCSV      outputFormat = "csv"
+	outputYAML     outputFormat = "yaml"
+	outputTemplate outputFormat = "template"
+)
+
+const outputUsage = "Output format: table, json, jsonl, csv, yaml or template=<go template>, e.g. 'template={{.Title}}'"
+
+// printer writes todos in the format picked with --output. The zero value
+// prints tables.
+type printer struct {
+	format outputFormat
+	tmpl   *template.Template
+	w      io.Writer
+}
+
+func newPrinter(value string, w io.Writer) (*printer, error) {
+	name, text, hasTemplate := strings.Cut(value, "=")
+
+	p := &printer{format: outputFormat(strings.ToLower(strings.TrimSpace(name))), w: w}
+	switch p.format {
+	case outputTable, outputJSON, outputJSONL, outputCSV, outputYAML:
+		if hasTemplate {
+			return nil, fmt.Errorf("output format %q does not take a template", p.format)
+		}
+	case outputTemplate:
+		if text == "" {
+			return nil, fmt.Errorf("template output needs a template, e.g. 'template={{.Title}}'")
+		}
+
+		tmpl, err := template.New("output").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		p.tmpl = tmpl
+	default:
+		return nil, fmt.Errorf("unknown output format %q (expected table, json, jsonl, csv, yaml or template)", name)
+	}
+
+	return p, nil
+}
+
+func (p *printer) isTable() bool {
+	return p == nil || p.format == "" || p.format == outputTable
+}
+
+// writeTodos prints a list of todos in a machine-readable format.
+func (p *printer) writeTodos(todos []*domain.Todo) error {
+	if todos == nil {
+		todos = []*domain.Todo{}
+	}
+
+	switch p.format {
+	case outputJSON:
+		return p.writeJSON(todos)
+	case outputYAML:
+		return p.writeYAML(todos)
+	case outputCSV:
+		return p.writeCSV(todos)
+	}
+
+	for _, todo := range todos {
+		if err := p.writeTodo(todo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTodo prints a single todo in a machine-readable format.
+func (p *printer) writeTodo(todo *domain.Todo) error {
+	switch p.format {
+	case outputJSON:
+		return p.writeJSON(todo)
+	case outputJSONL:
+		return json.NewEncoder(p.w).Encode(todo)
+	case outputYAML:
+		return p.writeYAML(todo)
+	case outputCSV:
+		return p.writeCSV([]*domain.Todo{todo})
+	default:
+		return p.writeTemplate(todo)
+	}
+}
+
+// writeValue prints something other than todos, such as statistics. CSV
+// is only defined for todos.
+func (p *printer) writeValue(v any) error {
+	switch p.format {
+	case outputJSON:
+		return p.writeJSON(v)
+	case outputJSONL:
+		return json.NewEncoder(p.w).Encode(v)
+	case outputYAML:
+		return p.writeYAML(v)
+	case outputCSV:
+		return fmt.Errorf("csv output is only available for todos")
+	default:
+		return p.writeTemplate(v)
+	}
+}
+
+// writeTemplate executes the template on v, ending the output with a newline.
+func (p *printer) writeTemplate(v any) error {
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, v); err != nil {
+		return err
+	}
+	out := sb.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(p.w, out)
+	return err
+}
+
+func (p *printer) writeJSON(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML goes through JSON so that keys follow the json tags on the domain
+// types, in declaration order.
+func (p *printer) writeYAML(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(p.w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style and quoting the JSON input left on node, so
+// the encoder picks idiomatic YAML again. Empty collections stay inline.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	if (node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode) && len(node.Content) == 0 {
+		node.Style = yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+var csvHeader = []string{
+	"id", "title", "description", "completed", "priority", "due_date", "tags", "project_id", "parent_id",
+	"recurrence", "subtasks_done", "subtasks_total", "open_blockers", "created_at", "updated_at",
+	"version", "completed_at", "archived_at",
+}
+
+func (p *printer) writeCSV(todos []*domain.Todo) error {
+	w := csv.NewWriter(p.w)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, todo := range todos {
+		record := []string{
+			todo.ID.String(),
+			todo.Title,
+			todo.Description,
+			strconv.FormatBool(todo.Completed),
+			todo.Priority.String(),
+			formatCSVTime(todo.DueDate),
+			strings.Join(todo.Tags, ","),
+			formatCSVID(todo.ProjectID),
+			formatCSVID(todo.ParentID),
+			todo.Recurrence,
+			strconv.Itoa(todo.Subtasks.Done),
+			strconv.Itoa(todo.Subtasks.Total),
+			strconv.Itoa(todo.OpenBlockers),
+			todo.CreatedAt.Format(time.RFC3339),
+			todo.UpdatedAt.Format(time.RFC3339),
+			strconv.Itoa(todo.Version),
+			formatCSVTime(todo.CompletedAt),
+			formatCSVTime(todo.ArchivedAt),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatCSVID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// emitTodos prints todos in the selected output format, calling table for the
+// human-readable rendering.
+func (cli *CLI) emitTodos(todos []*domain.Todo, table func()) error {
+	if cli.output.isTable() {
+		table()
+		return nil
+	}
+
+	if err := cli.output.writeTodos(todos); err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+	return nil
+}
+
+// emitTodo is emitTodos for a single todo.
+func (cli *CLI) emitTodo(todo *domain.Todo, table func()) error {
+	if cli.output.isTable() {
+		table()
+		return nil
+	}
+
+	if err := cli.output.writeTodo(todo); err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+	return nil
+}
+
+// emitValue is emitTodo for anything that is not a todo.
+func (cli *CLI) emitValue(v any, table func()) error {
+	if cli.output.isTable() {
+		table()
+		return nil
+	}
+	if cli.output.format == outputCSV {
+		return flagError("output", errors.New("csv is only available for todos"))
+	}
+
+	if err := cli.output.writeValue(v); err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTodos() []*domain.Todo {
+	created := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+	return []*domain.Todo{
+		{
+			ID:        uuid.MustParse("6f1c2a7e-3b7d-4c55-9d8e-1f2a3b4c5d6e"),
+			Title:     `Write, "docs"`,
+			Priority:  domain.PriorityHigh,
+			Tags:      []string{"docs", "infra"},
+			CreatedAt: created,
+			UpdatedAt: created,
+			Version:   1,
+		},
+		{
+			ID:          uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"),
+			Title:       "Ship",
+			Completed:   true,
+			CompletedAt: &created,
+			Tags:        []string{},
+			CreatedAt:   created,
+			UpdatedAt:   created,
+			Version:     3,
+		},
+	}
+}
+
+func TestNewPrinter_Invalid(t *testing.T) {
+	for _, value := range []string{"xml", "json=x", "template", "template={{.Title"} {
+		_, err := newPrinter(value, &bytes.Buffer{})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestPrinter_WriteTodos(t *testing.T) {
+	tests := map[string]string{
+		"jsonl": `{"id":"6f1c2a7e-3b7d-4c55-9d8e-1f2a3b4c5d6e","title":"Write, \"docs\"","description":"","completed":false,"priority":"high","tags":["docs","infra"],"subtasks":{"done":0,"total":0},"open_blockers":0,"created_at":"2025-03-12T10:30:00Z","updated_at":"2025-03-12T10:30:00Z","version":1}
+{"id":"0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d","title":"Ship","description":"","completed":true,"completed_at":"2025-03-12T10:30:00Z","priority":"none","tags":[],"subtasks":{"done":0,"total":0},"open_blockers":0,"created_at":"2025-03-12T10:30:00Z","updated_at":"2025-03-12T10:30:00Z","version":3}
+`,
+		"csv": `id,title,description,completed,priority,due_date,tags,project_id,parent_id,recurrence,subtasks_done,subtasks_total,open_blockers,created_at,updated_at,version,completed_at,archived_at
+6f1c2a7e-3b7d-4c55-9d8e-1f2a3b4c5d6e,"Write, ""docs""",,false,high,,"docs,infra",,,,0,0,0,2025-03-12T10:30:00Z,2025-03-12T10:30:00Z,1,,
+0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d,Ship,,true,none,,,,,,0,0,0,2025-03-12T10:30:00Z,2025-03-12T10:30:00Z,3,2025-03-12T10:30:00Z,
+`,
+		`template={{.Title}} [{{join .Tags ","}}]`: `Write, "docs" [docs,infra]
+Ship []
+`,
+	}
+
+	for format, expected := range tests {
+		var out bytes.Buffer
+		p, err := newPrinter(format, &out)
+		require.NoError(t, err, format)
+
+		assert.NoError(t, p.writeTodos(testTodos()), format)
+		assert.Equal(t, expected, out.String(), format)
+	}
+}
+
+func TestPrinter_WriteTodos_JSON(t *testing.T) {
+	var out bytes.Buffer
+	p, err := newPrinter("json", &out)
+	require.NoError(t, err)
+
+	// An empty list is still a JSON array
+	assert.NoError(t, p.writeTodos(nil))
+	assert.JSONEq(t, "[]", out.String())
+
+	out.Reset()
+	assert.NoError(t, p.writeTodos(testTodos()))
+
+	var decoded []*domain.Todo
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, testTodos(), decoded)
+}
+
+func TestPrinter_WriteTodo_YAML(t *testing.T) {
+	var out bytes.Buffer
+	p, err := newPrinter("yaml", &out)
+	require.NoError(t, err)
+
+	assert.NoError(t, p.writeTodo(testTodos()[0]))
+	assert.Equal(t, `id: 6f1c2a7e-3b7d-4c55-9d8e-1f2a3b4c5d6e
+title: Write, "docs"
+description: ""
+completed: false
+priority: high
+tags:
+  - docs
+  - infra
+subtasks:
+  done: 0
+  total: 0
+open_blockers: 0
+created_at: "2025-03-12T10:30:00Z"
+updated_at: "2025-03-12T10:30:00Z"
+version: 1
+`, out.String())
+}
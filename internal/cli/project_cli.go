@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) projectCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Manage projects",
+	}
+
+	cmd.AddCommand(
+		cli.projectCreateCommand(),
+		cli.projectListCommand(),
+		cli.projectArchiveCommand(),
+		cli.projectRenameCommand(),
+	)
+
+	return cmd
+}
+
+func (cli *CLI) projectCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a new project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			desc, _ := cmd.Flags().GetString("description")
+			request := domain.CreateProjectRequest{
+				Name:        args[0],
+				Description: desc,
+			}
+
+			project, err := cli.projectService.CreateProject(cli.context(), request)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitValue(project, func() {
+				fmt.Printf("Project %q created successfully!\n", project.Name)
+			})
+		},
+	}
+
+	cmd.Flags().StringP("description", "d", "", "Description for the project")
+
+	return cmd
+}
+
+func (cli *CLI) projectListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+
+			projects, err := cli.projectService.FindAllProjects(cli.context(), all)
+			if err != nil {
+				return err
+			}
+
+			if projects == nil {
+				projects = []*domain.Project{}
+			}
+
+			return cli.emitValue(projects, func() {
+				if len(projects) == 0 {
+					fmt.Println("No projects found")
+					return
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NAME\tDESCRIPTION\tSTATUS\tCREATION DATE")
+				for _, project := range projects {
+					status := "Active"
+					if project.Archived {
+						status = "📦 Archived"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+						project.Name,
+						truncate(project.Description, 30),
+						status,
+						project.CreatedAt.Format("2006-01-02 15:04"),
+					)
+				}
+				w.Flush()
+			})
+		},
+	}
+
+	cmd.Flags().BoolP("all", "a", false, "Include archived projects")
+
+	return cmd
+}
+
+func (cli *CLI) projectArchiveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "archive [name]",
+		Short: "Archive a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cli.context()
+
+			project, err := cli.projectService.FindProjectByName(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			archived, err := cli.projectService.ArchiveProject(ctx, project.ID)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitValue(archived, func() {
+				fmt.Printf("Project %q archived successfully!\n", archived.Name)
+			})
+		},
+	}
+}
+
+func (cli *CLI) projectRenameCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename [name] [new-name]",
+		Short: "Rename a project",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cli.context()
+
+			project, err := cli.projectService.FindProjectByName(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			renamed, err := cli.projectService.RenameProject(ctx, project.ID, args[1])
+			if err != nil {
+				return err
+			}
+
+			return cli.emitValue(renamed, func() {
+				fmt.Printf("Project %q renamed to %q!\n", project.Name, renamed.Name)
+			})
+		},
+	}
+}
+
+// projectFlag resolves the --project flag to a project ID, returning nil when
+// the flag was left empty. Archived projects only resolve when allowArchived
+// is set, so new work cannot land in them.
+func (cli *CLI) projectFlag(cmd *cobra.Command, allowArchived bool) (*uuid.UUID, error) {
+	return cli.projectFlagNamed(cmd, "project", allowArchived)
+}
+
+// projectFlagNamed is projectFlag for a project flag with another name.
+func (cli *CLI) projectFlagNamed(cmd *cobra.Command, flag string, allowArchived bool) (*uuid.UUID, error) {
+	name, _ := cmd.Flags().GetString(flag)
+	if name == "" {
+		return nil, nil
+	}
+
+	project, err := cli.projectService.FindProjectByName(cli.context(), name)
+	if err != nil {
+		return nil, err
+	}
+	if project.Archived && !allowArchived {
+		return nil, fmt.Errorf("%w: project %q is archived", domain.ErrConflict, project.Name)
+	}
+
+	return &project.ID, nil
+}
+
+// projectName returns the name of the project with the given ID, or "-" when
+// the todo has no project.
+func (cli *CLI) projectName(id *uuid.UUID) string {
+	if id == nil {
+		return "-"
+	}
+
+	if cli.projectNames == nil {
+		cli.projectNames = map[uuid.UUID]string{}
+		projects, _ := cli.projectService.FindAllProjects(cli.context(), true)
+		for _, project := range projects {
+			cli.projectNames[project.ID] = project.Name
+		}
+	}
+
+	if name, ok := cli.projectNames[*id]; ok {
+		return name
+	}
+	return id.String()[:8]
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/naturaldate"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) statsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show how many todos were completed and how long they took",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, _ := cmd.Flags().GetString("since")
+			since, err := naturaldate.Parse(value, time.Now())
+			if err != nil {
+				return flagError("since", err)
+			}
+			daily, _ := cmd.Flags().GetBool("daily")
+
+			stats, err := cli.todoService.CompletionStats(cli.context(), since)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitValue(stats, func() {
+				printStats(stats, daily)
+			})
+		},
+	}
+
+	cmd.Flags().String("since", "-4w", "Start of the period (e.g. -7d, -4w, 2025-01-01)")
+	cmd.Flags().Bool("daily", false, "Break the throughput down per day instead of per week")
+
+	return cmd
+}
+
+func printStats(stats *domain.CompletionStats, daily bool) {
+	fmt.Printf("Completed since %s: %d todo(s)\n", stats.Since.Local().Format("2006-01-02"), stats.Completed)
+	if stats.Completed == 0 {
+		return
+	}
+
+	fmt.Printf("Throughput:       %.1f per day, %.1f per week\n",
+		float64(stats.Completed)/float64(len(stats.Days)),
+		float64(stats.Completed)/float64(len(stats.Days))*7)
+	fmt.Printf("Median lead time: %s\n\n", formatLeadTime(time.Duration(stats.MedianLeadTime)))
+
+	periods, heading := stats.Weeks, "WEEK OF"
+	if daily {
+		periods, heading = stats.Days, "DAY"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tDONE\t\n", heading)
+	for _, period := range periods {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", period.Start.Format("2006-01-02 Mon"), period.Count, strings.Repeat("█", period.Count))
+	}
+	w.Flush()
+}
+
+// formatLeadTime rounds d to the two largest of days, hours and minutes.
+func formatLeadTime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return "under a minute"
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) tagCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Add or remove tags on todos",
+	}
+
+	cmd.AddCommand(
+		cli.tagAddCommand(),
+		cli.tagRemoveCommand(),
+	)
+
+	return cmd
+}
+
+func (cli *CLI) tagAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [id] [tag]...",
+		Short: "Add tags to todos",
+		Long: `Add tags to a todo, to several todos listed before -- as in
+"tag add a1b2 c3d4 -- bug", or to every todo a filter matches, as in
+"tag add bug --project website".`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			refs, tags, err := splitTagArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+			request, single, err := cli.bulkRequest(cmd, domain.BulkTag, refs)
+			if err != nil {
+				return err
+			}
+			request.Tags = tags
+			if !single {
+				return cli.runBulk(request)
+			}
+
+			todo, err := cli.todoService.AddTags(cli.context(), request.IDs[0], tags)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Tags added successfully!\n")
+				cli.printTodo(todo)
+			})
+		},
+	}
+
+	addBulkFlags(cmd)
+
+	return cmd
+}
+
+func (cli *CLI) tagRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm [id] [tag]...",
+		Aliases: []string{"remove"},
+		Short:   "Remove tags from todos",
+		Long: `Remove tags from a todo, from several todos listed before -- as in
+"tag rm a1b2 c3d4 -- bug", or from every todo a filter matches, as in
+"tag rm sprint-12 --completed".`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			refs, tags, err := splitTagArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+			request, single, err := cli.bulkRequest(cmd, domain.BulkUntag, refs)
+			if err != nil {
+				return err
+			}
+			request.Tags = tags
+			if !single {
+				return cli.runBulk(request)
+			}
+
+			todo, err := cli.todoService.RemoveTags(cli.context(), request.IDs[0], tags)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Tags removed successfully!\n")
+				cli.printTodo(todo)
+			})
+		},
+	}
+
+	addBulkFlags(cmd)
+
+	return cmd
+}
+
+// splitTagArgs separates the todo IDs in the arguments of tag add and tag rm
+// from the tags: the IDs are the arguments before --, or without it the
+// first argument. With a filter every argument is a tag.
+func splitTagArgs(cmd *cobra.Command, args []string) (refs, tags []string, err error) {
+	dash := cmd.ArgsLenAtDash()
+	switch {
+	case slices.ContainsFunc(bulkFilterFlags, cmd.Flags().Changed):
+		if dash >= 0 {
+			return nil, nil, &usageError{err: errors.New("give todo ids or filters, not both")}
+		}
+		refs, tags = nil, args
+	case dash >= 0:
+		refs, tags = args[:dash], args[dash:]
+	default:
+		refs, tags = args[:1], args[1:]
+	}
+
+	if len(tags) == 0 {
+		return nil, nil, &usageError{err: errors.New("give at least one tag")}
+	}
+	return refs, tags, nil
+}
+
+func (cli *CLI) tagsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tags",
+		Short: "List tags with the number of todos carrying each",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tags, err := cli.todoService.FindAllTags(cli.context())
+			if err != nil {
+				return err
+			}
+
+			if tags == nil {
+				tags = []*domain.TagCount{}
+			}
+
+			return cli.emitValue(tags, func() {
+				if len(tags) == 0 {
+					fmt.Println("No tags found")
+					return
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TAG\tTODOS")
+				for _, tag := range tags {
+					fmt.Fprintf(w, "%s\t%d\n", tag.Name, tag.Count)
+				}
+				w.Flush()
+			})
+		},
+	}
+}
@@ -2,76 +2,81 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/leandrowiemesfilho/go-todo-cli/config"
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
-	"github.com/leandrowiemesfilho/go-todo-cli/internal/repository"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/naturaldate"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/recurrence"
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/service"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/storage"
 	"github.com/spf13/cobra"
 )
 
 type CLI struct {
-	rootCmd     *cobra.Command
-	todoService domain.TodoService
-	dbPool      *pgxpool.Pool
+	rootCmd        *cobra.Command
+	cfg            *config.Config
+	todoService    domain.TodoService
+	projectService domain.ProjectService
+	backend        *storage.Backend
+	output         *printer
+	// projectNames caches project names by ID for printing
+	projectNames map[uuid.UUID]string
+	// started is set once cobra has accepted the command line, so Execute
+	// can tell usage errors from failures of the command itself
+	started bool
+	// ctx is the context the running command makes its changes in; see
+	// context
+	ctx context.Context
 }
 
 func NewCLI() *CLI {
 	// Load configuration
-	cfg := config.LoadConfig()
-
-	// Create database connection pool
-	dbPool, err := createDBPool(cfg)
-	if err != nil {
-		log.Fatalf("Unable to create database connection pool: %v\n", err)
-	}
-
-	// Initialize repository and service
-	repo := repository.NewTodoRepository(dbPool)
-	todoService := service.NewTodoService(repo)
-
 	cli := &CLI{
-		todoService: todoService,
-		dbPool:      dbPool,
+		cfg: config.LoadConfig(),
 	}
 
 	cli.setupRootCommand()
 	return cli
 }
 
-func createDBPool(cfg *config.Config) (*pgxpool.Pool, error) {
-	dsn := cfg.GetPostgresDSN()
-	poolConfig, err := pgxpool.ParseConfig(dsn)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse connection string: %v", err)
+// connect opens the configured storage backend and wires the service on top
+// of it. Pending migrations are applied first unless autoMigrate is false or
+// disabled through TODO_AUTO_MIGRATE.
+func (cli *CLI) connect(cmd *cobra.Command, autoMigrate bool) error {
+	// Cobra only checks these after the pre-run hooks, which would report a
+	// missing flag after connecting, as a failure of the command
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return err
 	}
-
-	// Set connection pool settings
-	poolConfig.MaxConns = 10
-	poolConfig.MinConns = 2
-	poolConfig.MaxConnLifetime = time.Hour
-	poolConfig.MaxConnIdleTime = 30 * time.Minute
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return err
+	}
+	cli.started = true
 
 	ctx := context.Background()
-	dbPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+
+	backend, err := storage.Open(ctx, cli.cfg.GetBackendURL())
 	if err != nil {
-		return nil, fmt.Errorf("unable to create connection pool: %v", err)
+		return fmt.Errorf("unable to open storage backend: %w", err)
 	}
+	cli.backend = backend
 
-	// Test the connection
-	if err := dbPool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("unable to ping database: %v", err)
+	if autoMigrate && cli.cfg.AutoMigrate {
+		if _, err := backend.Migrator.Up(ctx); err != nil {
+			return fmt.Errorf("unable to migrate database schema: %w", err)
+		}
 	}
 
-	fmt.Println("✅ Successfully connected to PostgreSQL database")
-	return dbPool, nil
+	cli.todoService = service.NewTodoService(backend.Repository)
+	cli.projectService = service.NewProjectService(backend.ProjectRepository)
+	return nil
 }
 
 func (cli *CLI) setupRootCommand() {
@@ -79,62 +84,201 @@ func (cli *CLI) setupRootCommand() {
 		Use:   "todo",
 		Short: "A simple CLI todo application",
 		Long:  "A command-line interface for managing your todos with persistence",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			// Ensure database connection is healthy
-			if err := cli.dbPool.Ping(context.Background()); err != nil {
-				fmt.Printf("❌ Database connection lost: %v\n", err)
-				os.Exit(1)
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			printer, err := newPrinter(output, os.Stdout)
+			if err != nil {
+				return flagError("output", err)
 			}
+			cli.output = printer
+
+			return cli.connect(cmd, true)
 		},
+		// Errors are printed once, by main, which also picks the exit code
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 
+	cli.rootCmd.PersistentFlags().StringP("output", "o", string(outputTable), outputUsage)
+
 	cli.rootCmd.AddCommand(
 		cli.findAllCommand(),
 		cli.findByIDCommand(),
 		cli.createCommand(),
 		cli.updateCommand(),
 		cli.deleteCommand(),
+		cli.restoreCommand(),
+		cli.trashCommand(),
 		cli.toggleCommand(),
+		cli.doneCommand(),
+		cli.reopenCommand(),
+		cli.moveCommand(),
+		cli.historyCommand(),
+		cli.undoCommand(),
+		cli.redoCommand(),
+		cli.archiveCommand(),
+		cli.unarchiveCommand(),
+		cli.tagCommand(),
+		cli.tagsCommand(),
+		cli.blockCommand(),
+		cli.unblockCommand(),
+		cli.projectCommand(),
+		cli.statsCommand(),
+		cli.migrateCommand(),
 	)
 }
 
+// Execute runs the command line. Errors cobra raises before the command
+// starts, such as unknown flags or a wrong number of arguments, are returned
+// as usage errors; see ExitCode.
 func (cli *CLI) Execute() error {
-	defer cli.dbPool.Close()
-	return cli.rootCmd.Execute()
+	defer func() {
+		if cli.backend != nil {
+			cli.backend.Close()
+		}
+	}()
+
+	err := cli.rootCmd.Execute()
+	if err != nil && !cli.started {
+		return &usageError{err: err}
+	}
+	return err
 }
 
+const repeatUsage = "Repeat the todo: daily, weekly, monthly, yearly, weekdays, \"every 2 weeks\" or an RRULE such as FREQ=WEEKLY;BYDAY=MO,TH"
+
 func (cli *CLI) findAllCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all todos",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			filterCompleted, _ := cmd.Flags().GetBool("completed")
 			filterPending, _ := cmd.Flags().GetBool("pending")
+			filterOverdue, _ := cmd.Flags().GetBool("overdue")
+			filterBlocked, _ := cmd.Flags().GetBool("blocked")
+			filterReady, _ := cmd.Flags().GetBool("ready")
+			archived, _ := cmd.Flags().GetBool("archived")
+			all, _ := cmd.Flags().GetBool("all")
+			search, _ := cmd.Flags().GetString("search")
+
+			dueBefore, err := parseDateFlag(cmd, "due-before")
+			if err != nil {
+				return flagError("due-before", err)
+			}
+			dueAfter, err := parseDateFlag(cmd, "due-after")
+			if err != nil {
+				return flagError("due-after", err)
+			}
+			createdBefore, err := parseDateFlag(cmd, "created-before")
+			if err != nil {
+				return flagError("created-before", err)
+			}
+			createdAfter, err := parseDateFlag(cmd, "created-after")
+			if err != nil {
+				return flagError("created-after", err)
+			}
+
+			limit, _ := cmd.Flags().GetInt("limit")
+			page, _ := cmd.Flags().GetInt("page")
+			if page < 1 {
+				return flagError("page", errors.New("pages start at 1"))
+			}
+			if page > 1 && limit <= 0 {
+				return flagError("page", errors.New("only valid with --limit"))
+			}
 
-			todos, err := cli.todoService.FindAllTodos(context.Background())
+			sortFlag, _ := cmd.Flags().GetString("sort")
+			sort, err := domain.ParseSort(sortFlag)
 			if err != nil {
-				fmt.Printf("Error getting TODOs: %v\n", err)
-				return
+				return flagError("sort", err)
 			}
 
-			if len(todos) == 0 {
-				fmt.Println("No TODOs found")
+			tags, _ := cmd.Flags().GetStringArray("tag")
+			projectID, err := cli.projectFlag(cmd, true)
+			if err != nil {
+				return err
 			}
 
-			var filteredTodos []*domain.Todo
-			for _, todo := range todos {
-				if filterCompleted && !todo.Completed {
-					continue
-				} else if filterPending && todo.Completed {
-					continue
+			query := domain.TodoQuery{
+				Text:            search,
+				DueBefore:       dueBefore,
+				DueAfter:        dueAfter,
+				CreatedBefore:   createdBefore,
+				CreatedAfter:    createdAfter,
+				Tags:            tags,
+				ProjectID:       projectID,
+				Archived:        archived,
+				IncludeArchived: all,
+				Sort:            sort,
+				Limit:           limit,
+				Offset:          (page - 1) * limit,
+			}
+			if filterBlocked {
+				query.Blocked = &filterBlocked
+			} else if filterReady {
+				// Ready means pending with nothing left to wait on
+				blocked := false
+				query.Blocked = &blocked
+				filterPending = true
+			}
+			if filterOverdue {
+				filterPending = true
+				if now := time.Now(); query.DueBefore == nil || now.Before(*query.DueBefore) {
+					query.DueBefore = &now
 				}
+			}
+			if filterCompleted {
+				query.Completed = &filterCompleted
+			} else if filterPending {
+				completed := false
+				query.Completed = &completed
+			}
 
-				filteredTodos = append(filteredTodos, todo)
-
-				cli.printTodoTable(filteredTodos)
+			todos, err := cli.todoService.FindAllTodos(cli.context(), query)
+			if err != nil {
+				return err
 			}
+
+			return cli.emitTodos(todos, func() {
+				if len(todos) == 0 {
+					fmt.Println("No TODOs found")
+					return
+				}
+
+				if tree, _ := cmd.Flags().GetBool("tree"); tree {
+					cli.printTodoTable(subtaskTree(todos))
+					return
+				}
+				cli.printTodoTable(todos, nil)
+			})
 		},
 	}
+
+	cmd.Flags().Bool("completed", false, "Show only completed todos")
+	cmd.Flags().Bool("pending", false, "Show only pending todos")
+	cmd.Flags().Bool("overdue", false, "Show only pending todos past their due date")
+	cmd.Flags().Bool("blocked", false, "Show only todos waiting on open blockers")
+	cmd.Flags().Bool("ready", false, "Show only pending todos with no open blockers")
+	cmd.Flags().String("due-before", "", "Show only todos due before this date (e.g. fri, +7d, 2025-01-31)")
+	cmd.Flags().String("due-after", "", "Show only todos due after this date (e.g. today, -1w, 2025-01-01)")
+	cmd.Flags().StringArray("tag", nil, "Show only todos with this tag (repeatable, all must match)")
+	cmd.Flags().String("project", "", "Show only todos in this project")
+	cmd.Flags().String("sort", "created", "Comma-separated sort order of priority, due and created; prefix a field with - to reverse it")
+	cmd.Flags().StringP("search", "s", "", "Show only todos whose title or description contains this text")
+	cmd.Flags().String("created-before", "", "Show only todos created before this date")
+	cmd.Flags().String("created-after", "", "Show only todos created after this date")
+	cmd.Flags().IntP("limit", "n", 0, "Show at most this many todos (0 for all)")
+	cmd.Flags().Int("page", 1, "Page of --limit todos to show, starting at 1")
+	cmd.Flags().Bool("tree", false, "Nest subtasks under their parent")
+	cmd.Flags().Bool("archived", false, "Show only archived todos")
+	cmd.Flags().Bool("all", false, "Show archived todos too")
+	cmd.MarkFlagsMutuallyExclusive("archived", "all")
+	cmd.MarkFlagsMutuallyExclusive("blocked", "ready")
+	cmd.MarkFlagsMutuallyExclusive("completed", "pending")
+	cmd.MarkFlagsMutuallyExclusive("completed", "overdue")
+	cmd.MarkFlagsMutuallyExclusive("completed", "ready")
+
+	return cmd
 }
 
 func (cli *CLI) findByIDCommand() *cobra.Command {
@@ -142,20 +286,47 @@ func (cli *CLI) findByIDCommand() *cobra.Command {
 		Use:   "find [id]",
 		Short: "Find a specific todo by ID",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			id, err := uuid.Parse(args[0])
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := cli.resolveID(args[0])
 			if err != nil {
-				fmt.Printf("Error parsing id: %v\n", err)
-				return
+				return err
 			}
 
-			todo, err := cli.todoService.FindTodoByID(context.Background(), id)
+			ctx := cli.context()
+
+			todo, err := cli.todoService.FindTodoByID(ctx, id)
 			if err != nil {
-				fmt.Printf("Error getting TODO: %v\n", err)
-				return
+				return err
+			}
+
+			if !cli.output.isTable() {
+				return cli.emitTodo(todo, nil)
 			}
 
 			cli.printTodo(todo)
+
+			blockers, err := cli.todoService.FindBlockers(ctx, id)
+			if err != nil {
+				return fmt.Errorf("unable to get blockers: %w", err)
+			}
+			if len(blockers) > 0 {
+				fmt.Println("Blocked by:")
+				cli.printTodoTable(blockers, nil)
+				fmt.Println()
+			}
+
+			if todo.Subtasks.Total == 0 {
+				return nil
+			}
+
+			subtasks, err := cli.todoService.FindSubtasks(ctx, id)
+			if err != nil {
+				return fmt.Errorf("unable to get subtasks: %w", err)
+			}
+
+			cli.printTodoTable(subtaskTree(subtasks))
+
+			return nil
 		},
 	}
 }
@@ -165,26 +336,63 @@ func (cli *CLI) createCommand() *cobra.Command {
 		Use:   "create [title]",
 		Short: "Create a new TODO item",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			desc, _ := cmd.Flags().GetString("description")
+			dueDate, err := parseDateFlag(cmd, "due")
+			if err != nil {
+				return flagError("due", err)
+			}
+
+			priority, err := parsePriorityFlag(cmd)
+			if err != nil {
+				return flagError("priority", err)
+			}
+
+			tags, _ := cmd.Flags().GetStringArray("tag")
+			repeat, _ := cmd.Flags().GetString("repeat")
+			projectID, err := cli.projectFlag(cmd, false)
+			if err != nil {
+				return err
+			}
+
+			parentID, err := cli.idFlag(cmd, "parent")
+			if err != nil {
+				return fmt.Errorf("parent: %w", err)
+			}
+
 			request := domain.CreateTodoRequest{
 				Title:       args[0],
 				Description: desc,
+				DueDate:     dueDate,
+				Tags:        tags,
+				ProjectID:   projectID,
+				ParentID:    parentID,
+				Recurrence:  repeat,
+			}
+			if priority != nil {
+				request.Priority = *priority
 			}
 
-			todo, err := cli.todoService.CreateTodo(context.Background(), request)
+			todo, err := cli.todoService.CreateTodo(cli.context(), request)
 			if err != nil {
-				fmt.Printf("Error creating TODO %v\n", err)
-				return
+				return err
 			}
 
-			fmt.Printf("TODO created successfully!\n")
-			cli.printTodo(todo)
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("TODO created successfully!\n")
+				cli.printTodo(todo)
+			})
 		},
 	}
 
 	cmd.Flags().StringP("title", "t", "", "New title for the todo")
 	cmd.Flags().StringP("description", "d", "", "New description for the todo")
+	cmd.Flags().String("due", "", "Due date (e.g. tomorrow, \"fri 17:00\", +3d, 2025-01-31)")
+	cmd.Flags().StringP("priority", "p", "", "Priority: none, low, medium, high or critical")
+	cmd.Flags().StringArray("tag", nil, "Tag for the todo (repeatable)")
+	cmd.Flags().String("project", "", "Project for the todo")
+	cmd.Flags().String("parent", "", "Make the todo a subtask of this todo")
+	cmd.Flags().String("repeat", "", repeatUsage)
 
 	return cmd
 }
@@ -194,101 +402,331 @@ func (cli *CLI) updateCommand() *cobra.Command {
 		Use:   "update [id]",
 		Short: "Update a TODO item",
 		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			id, err := uuid.Parse(args[0])
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := cli.resolveID(args[0])
 			if err != nil {
-				fmt.Printf("Error parsing id: %v\n", err)
-				return
+				return err
+			}
+
+			clearDescription, _ := cmd.Flags().GetBool("clear-description")
+			clearDue, _ := cmd.Flags().GetBool("clear-due")
+			dueDate, err := parseDateFlag(cmd, "due")
+			if err != nil {
+				return flagError("due", err)
+			}
+
+			priority, err := parsePriorityFlag(cmd)
+			if err != nil {
+				return flagError("priority", err)
+			}
+
+			clearProject, _ := cmd.Flags().GetBool("clear-project")
+			projectID, err := cli.projectFlag(cmd, false)
+			if err != nil {
+				return err
+			}
+
+			clearRepeat, _ := cmd.Flags().GetBool("clear-repeat")
+
+			clearParent, _ := cmd.Flags().GetBool("clear-parent")
+			parentID, err := cli.idFlag(cmd, "parent")
+			if err != nil {
+				return fmt.Errorf("parent: %w", err)
 			}
 
-			title, _ := cmd.Flags().GetString("title")
-			description, _ := cmd.Flags().GetString("description")
 			request := domain.UpdateTodoRequest{
-				ID:          id,
-				Title:       title,
-				Description: description,
+				ID:                id,
+				Title:             changedString(cmd, "title"),
+				Description:       changedString(cmd, "description"),
+				ClearDescription:  clearDescription,
+				AppendDescription: changedString(cmd, "append-description"),
+				Priority:          priority,
+				DueDate:           dueDate,
+				ClearDueDate:      clearDue,
+				ProjectID:         projectID,
+				ClearProject:      clearProject,
+				ParentID:          parentID,
+				ClearParent:       clearParent,
+				Recurrence:        changedString(cmd, "repeat"),
+				ClearRecurrence:   clearRepeat,
 			}
+			request.Version, _ = cmd.Flags().GetInt("if-version")
 
-			todo, err := cli.todoService.UpdateTodo(context.Background(), request)
+			ctx := cli.context()
+			todo, err := cli.todoService.UpdateTodo(ctx, request)
+			if errors.Is(err, domain.ErrStale) {
+				if current, findErr := cli.todoService.FindTodoByID(ctx, id); findErr == nil {
+					writeConflict(os.Stderr, current, request, cli.projectName)
+				}
+			}
 			if err != nil {
-				fmt.Printf("Error trying to update TOD item %v\n", err)
-				return
+				return err
 			}
 
-			fmt.Printf("TODO updated successfully!\n")
-			cli.printTodo(todo)
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("TODO updated successfully!\n")
+				cli.printTodo(todo)
+			})
 		},
 	}
 
 	cmd.Flags().StringP("title", "t", "", "New title for the todo")
 	cmd.Flags().StringP("description", "d", "", "New description for the todo")
+	cmd.Flags().Bool("clear-description", false, "Remove the description")
+	cmd.Flags().StringP("append-description", "a", "", "Add a line to the end of the description")
+	cmd.Flags().String("due", "", "New due date (e.g. tomorrow, \"fri 17:00\", +3d, 2025-01-31)")
+	cmd.Flags().Bool("clear-due", false, "Remove the due date")
+	cmd.Flags().StringP("priority", "p", "", "New priority: none, low, medium, high or critical")
+	cmd.Flags().String("project", "", "Move the todo to this project")
+	cmd.Flags().Bool("clear-project", false, "Remove the todo from its project")
+	cmd.Flags().String("parent", "", "Move the todo under this todo")
+	cmd.Flags().Bool("clear-parent", false, "Turn the subtask back into a top-level todo")
+	cmd.Flags().String("repeat", "", repeatUsage)
+	cmd.Flags().Bool("clear-repeat", false, "Stop the todo from repeating")
+	cmd.Flags().Int("if-version", 0, "Only update the todo if it is still at this version, as shown by find")
+	cmd.MarkFlagsMutuallyExclusive("description", "clear-description")
+	cmd.MarkFlagsMutuallyExclusive("append-description", "clear-description")
+	cmd.MarkFlagsMutuallyExclusive("project", "clear-project")
+	cmd.MarkFlagsMutuallyExclusive("parent", "clear-parent")
+	cmd.MarkFlagsMutuallyExclusive("repeat", "clear-repeat")
+	cmd.MarkFlagsMutuallyExclusive("due", "clear-due")
 
 	return cmd
 }
 
 func (cli *CLI) deleteCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "delete [id]",
-		Short: "Delete a TODO item by ID",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			id, err := uuid.Parse(args[0])
+	cmd := &cobra.Command{
+		Use:   "delete [id]...",
+		Short: "Move TODO items and their subtasks to the trash",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request, single, err := cli.bulkRequest(cmd, domain.BulkDelete, args)
 			if err != nil {
-				fmt.Printf("Error parsing id: %v\n", err)
-				return
+				return err
+			}
+			if !single {
+				return cli.runBulk(request)
 			}
 
-			if err = cli.todoService.DeleteTodo(context.Background(), id); err != nil {
-				fmt.Printf("Error trying to delete a TODO item %v\n", err)
-				return
+			ctx := cli.context()
+			id := request.IDs[0]
+
+			// Machine-readable output reports the todo as it was before deletion
+			var todo *domain.Todo
+			if !cli.output.isTable() {
+				if todo, err = cli.todoService.FindTodoByID(ctx, id); err != nil {
+					return err
+				}
+			}
+
+			if err = cli.todoService.DeleteTodo(ctx, id); err != nil {
+				return err
 			}
 
-			fmt.Printf("Todo deleted successfully!\n")
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Todo moved to the trash. Undo with: todo restore %s\n", id.String()[:8])
+			})
 		},
 	}
+
+	addBulkFlags(cmd)
+
+	return cmd
+}
+
+// staleAttempts is how often toggle, done and reopen try again when the todo
+// changes under them.
+const staleAttempts = 3
+
+// retryStale runs change until it stops failing with domain.ErrStale, which
+// means it wrote nothing and can safely run again on a fresh copy.
+func retryStale(change func() (*domain.Todo, error)) (*domain.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		todo, err := change()
+		if !errors.Is(err, domain.ErrStale) || attempt == staleAttempts {
+			return todo, err
+		}
+	}
 }
 
 func (cli *CLI) toggleCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "toggle [id]",
+	cmd := &cobra.Command{
+		Use:   "toggle [id]...",
 		Short: "Toggle todo completion status",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			id, err := uuid.Parse(args[0])
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cascade, _ := cmd.Flags().GetBool("cascade")
+			force, _ := cmd.Flags().GetBool("force")
+
+			request, single, err := cli.bulkRequest(cmd, domain.BulkToggle, args)
 			if err != nil {
-				fmt.Printf("Error parsing id: %v\n", err)
-				return
+				return err
+			}
+			if !single {
+				if cascade {
+					return flagError("cascade", errors.New("only works on a single todo"))
+				}
+				request.Force = force
+				return cli.runBulk(request)
 			}
 
-			todo, err := cli.todoService.ToggleTodo(context.Background(), id)
+			ctx := cli.context()
+			id := request.IDs[0]
+
+			todo, err := retryStale(func() (*domain.Todo, error) {
+				return cli.todoService.ToggleTodo(ctx, id, domain.ToggleOptions{Cascade: cascade, Force: force})
+			})
 			if err != nil {
-				fmt.Printf("Error toggling TODO: %v\n", err)
-				return
+				return err
 			}
 
 			status := "completed"
 			if !todo.Completed {
 				status = "pending"
 			}
-			fmt.Printf("Todo marked as %s!\n", status)
-			cli.printTodo(todo)
+			return cli.emitCompletion(ctx, todo, fmt.Sprintf("Todo marked as %s!", status))
 		},
 	}
+
+	cmd.Flags().Bool("cascade", false, "Also complete every subtask")
+	cmd.Flags().Bool("force", false, "Complete the todo, and with --cascade its subtasks, even if blocked by open todos")
+	addBulkFlags(cmd)
+
+	return cmd
 }
 
-func (cli *CLI) printTodoTable(todos []*domain.Todo) {
+func (cli *CLI) doneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "done [id]...",
+		Short: "Mark TODOs as completed; completed TODOs are left as they are",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cascade, _ := cmd.Flags().GetBool("cascade")
+			force, _ := cmd.Flags().GetBool("force")
+
+			request, single, err := cli.bulkRequest(cmd, domain.BulkComplete, args)
+			if err != nil {
+				return err
+			}
+			if !single {
+				if cascade {
+					return flagError("cascade", errors.New("only works on a single todo"))
+				}
+				request.Force = force
+				return cli.runBulk(request)
+			}
+
+			ctx := cli.context()
+			id := request.IDs[0]
+
+			before, err := cli.todoService.FindTodoByID(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			todo, err := retryStale(func() (*domain.Todo, error) {
+				return cli.todoService.CompleteTodo(ctx, id, domain.ToggleOptions{Cascade: cascade, Force: force})
+			})
+			if err != nil {
+				return err
+			}
+
+			message := "Todo marked as completed!"
+			if before.Completed {
+				message = "Todo is already completed."
+			}
+			return cli.emitCompletion(ctx, todo, message)
+		},
+	}
+
+	cmd.Flags().Bool("cascade", false, "Also complete every subtask")
+	cmd.Flags().Bool("force", false, "Complete the todo, and with --cascade its subtasks, even if blocked by open todos")
+	addBulkFlags(cmd)
+
+	return cmd
+}
+
+func (cli *CLI) reopenCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reopen [id]",
+		Short: "Mark a TODO as pending again; pending TODOs are left as they are",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := cli.resolveID(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx := cli.context()
+			before, err := cli.todoService.FindTodoByID(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			todo, err := retryStale(func() (*domain.Todo, error) {
+				return cli.todoService.ReopenTodo(ctx, id)
+			})
+			if err != nil {
+				return err
+			}
+
+			message := "Todo marked as pending!"
+			if !before.Completed {
+				message = "Todo is already pending."
+			}
+			return cli.emitCompletion(ctx, todo, message)
+		},
+	}
+}
+
+// emitCompletion reports a todo whose completion status was just set,
+// with message heading the details in table output.
+func (cli *CLI) emitCompletion(ctx context.Context, todo *domain.Todo, message string) error {
+	return cli.emitTodo(todo, func() {
+		fmt.Println(message)
+		if todo.Completed && todo.OpenBlockers > 0 {
+			fmt.Printf("⚠️  Completed while still blocked by %d open todo(s)\n", todo.OpenBlockers)
+		}
+		cli.printTodo(todo)
+
+		if next := todo.NextOccurrence; next != nil {
+			fmt.Printf("🔁 Next occurrence %s due %s\n", next.ID.String()[:8], formatDue(next, time.Now()))
+		}
+
+		if todo.ParentID != nil {
+			// The change already succeeded, so this only warns
+			parent, err := cli.todoService.FindTodoByID(ctx, *todo.ParentID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting parent TODO: %v\n", err)
+				return
+			}
+			fmt.Printf("Parent %q: %s\n", parent.Title, parent.Subtasks)
+		}
+	})
+}
+
+// printTodoTable prints todos one per row. When depths is given, each title
+// is indented to the todo's depth in the subtask tree.
+func (cli *CLI) printTodoTable(todos []*domain.Todo, depths []int) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tCREATION DATE")
+	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tPRIORITY\tDUE\tREPEAT\tPROJECT\tTAGS\tCREATION DATE")
 
-	for _, todo := range todos {
-		status := "❌ Pending"
-		if todo.Completed {
-			status = "✅ Completed"
+	now := time.Now()
+	for i, todo := range todos {
+		title := truncate(todo.Title, 20)
+		if depths != nil && depths[i] > 0 {
+			title = strings.Repeat("  ", depths[i]-1) + "└─ " + title
+		}
+		if todo.Subtasks.Total > 0 {
+			title += fmt.Sprintf(" (%d/%d)", todo.Subtasks.Done, todo.Subtasks.Total)
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			todo.ID.String()[:8],
-			truncate(todo.Title, 20),
-			status,
+			title,
+			formatStatus(todo),
+			formatPriority(todo.Priority),
+			formatDue(todo, now),
+			truncate(formatRecurrence(todo.Recurrence), 24),
+			truncate(cli.projectName(todo.ProjectID), 15),
+			formatTags(todo.Tags),
 			todo.CreatedAt.Format("2006-01-02 15:04"),
 		)
 	}
@@ -296,10 +734,7 @@ func (cli *CLI) printTodoTable(todos []*domain.Todo) {
 }
 
 func (cli *CLI) printTodo(todo *domain.Todo) {
-	status := "❌ Pending"
-	if todo.Completed {
-		status = "✅ Completed"
-	}
+	status := formatStatus(todo)
 
 	fmt.Printf("\nTodo Details:\n")
 	fmt.Printf("  ID:          %s\n", todo.ID)
@@ -308,11 +743,217 @@ func (cli *CLI) printTodo(todo *domain.Todo) {
 		fmt.Printf("  Description: %s\n", todo.Description)
 	}
 	fmt.Printf("  Status:      %s\n", status)
+	if todo.CompletedAt != nil {
+		fmt.Printf("  Completed:   %s\n", todo.CompletedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("  Priority:    %s\n", formatPriority(todo.Priority))
+	if todo.DueDate != nil {
+		fmt.Printf("  Due:         %s\n", formatDue(todo, time.Now()))
+	}
+	if todo.Recurrence != "" {
+		fmt.Printf("  Repeats:     %s\n", formatRecurrence(todo.Recurrence))
+	}
+	if todo.ProjectID != nil {
+		fmt.Printf("  Project:     %s\n", cli.projectName(todo.ProjectID))
+	}
+	if todo.ParentID != nil {
+		fmt.Printf("  Parent:      %s\n", todo.ParentID)
+	}
+	if todo.Subtasks.Total > 0 {
+		fmt.Printf("  Subtasks:    %s\n", todo.Subtasks)
+	}
+	if todo.OpenBlockers > 0 {
+		fmt.Printf("  Blockers:    %d open\n", todo.OpenBlockers)
+	}
+	if len(todo.Tags) > 0 {
+		fmt.Printf("  Tags:        %s\n", formatTags(todo.Tags))
+	}
+	if todo.ArchivedAt != nil {
+		fmt.Printf("  Archived:    %s\n", todo.ArchivedAt.Local().Format("2006-01-02 15:04:05"))
+	}
 	fmt.Printf("  Created:     %s\n", todo.CreatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("  Updated:     %s\n", todo.UpdatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Version:     %d\n", todo.Version)
 	fmt.Println()
 }
 
+func formatStatus(todo *domain.Todo) string {
+	switch {
+	case todo.Completed:
+		return "✅ Completed"
+	case todo.IsBlocked():
+		return "⛔ Blocked"
+	default:
+		return "❌ Pending"
+	}
+}
+
+func formatPriority(priority domain.Priority) string {
+	switch priority {
+	case domain.PriorityNone:
+		return "-"
+	case domain.PriorityCritical:
+		return "🔥 critical"
+	default:
+		return priority.String()
+	}
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	return truncate(strings.Join(tags, ","), 30)
+}
+
+func formatDue(todo *domain.Todo, now time.Time) string {
+	if todo.DueDate == nil {
+		return "-"
+	}
+
+	due := todo.DueDate.Format("2006-01-02 15:04")
+	if todo.IsOverdue(now) {
+		due += " ⚠️ overdue"
+	}
+	return due
+}
+
+// formatRecurrence describes a stored RRULE, falling back to the raw rule if
+// it cannot be parsed.
+func formatRecurrence(rule string) string {
+	if rule == "" {
+		return "-"
+	}
+
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return rule
+	}
+	return parsed.Describe()
+}
+
+// parseDateFlag reads a natural-language date flag, returning nil when the
+// flag was left empty.
+func parseDateFlag(cmd *cobra.Command, name string) (*time.Time, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := naturaldate.Parse(value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseAgeFlag reads an age flag such as 30d or 2w and returns the moment
+// that long ago, or nil when the flag was left empty.
+func parseAgeFlag(cmd *cobra.Command, name string) (*time.Time, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := naturaldate.Parse("-"+value, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("expected an age such as 30d, 2w or 12h, got %q", value)
+	}
+	return &t, nil
+}
+
+// changedString reads a string flag, returning nil when it was not given so
+// that an explicit empty value can still be told apart.
+func changedString(cmd *cobra.Command, name string) *string {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+
+	value, _ := cmd.Flags().GetString(name)
+	return &value
+}
+
+// context returns the context commands run in, which records the configured
+// actor as the author of their changes. Everything a command changes is one
+// operation, so undo takes it back as a whole.
+func (cli *CLI) context() context.Context {
+	if cli.ctx == nil {
+		cli.ctx = domain.WithOperation(domain.WithActor(context.Background(), cli.cfg.Actor))
+	}
+	return cli.ctx
+}
+
+// resolveID accepts a full todo ID or any unique prefix of one, such as the
+// short IDs list prints.
+func (cli *CLI) resolveID(ref string) (uuid.UUID, error) {
+	return cli.todoService.ResolveTodoID(cli.context(), ref)
+}
+
+// idFlag reads a todo ID flag, returning nil when the flag was left empty.
+func (cli *CLI) idFlag(cmd *cobra.Command, name string) (*uuid.UUID, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := cli.resolveID(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// subtaskTree orders todos depth first so every subtask follows its parent,
+// keeping the original order among siblings. It returns each todo's depth
+// alongside it; todos whose parent is not in the list are roots.
+func subtaskTree(todos []*domain.Todo) ([]*domain.Todo, []int) {
+	present := make(map[uuid.UUID]bool, len(todos))
+	for _, todo := range todos {
+		present[todo.ID] = true
+	}
+
+	var roots []*domain.Todo
+	children := map[uuid.UUID][]*domain.Todo{}
+	for _, todo := range todos {
+		if todo.ParentID != nil && present[*todo.ParentID] {
+			children[*todo.ParentID] = append(children[*todo.ParentID], todo)
+		} else {
+			roots = append(roots, todo)
+		}
+	}
+
+	ordered := make([]*domain.Todo, 0, len(todos))
+	depths := make([]int, 0, len(todos))
+	var walk func(todo *domain.Todo, depth int)
+	walk = func(todo *domain.Todo, depth int) {
+		ordered = append(ordered, todo)
+		depths = append(depths, depth)
+		for _, child := range children[todo.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return ordered, depths
+}
+
+// parsePriorityFlag reads the --priority flag, returning nil when it was not
+// given.
+func parsePriorityFlag(cmd *cobra.Command) (*domain.Priority, error) {
+	if !cmd.Flags().Changed("priority") {
+		return nil, nil
+	}
+
+	value, _ := cmd.Flags().GetString("priority")
+	priority, err := domain.ParsePriority(value)
+	if err != nil {
+		return nil, err
+	}
+	return &priority, nil
+}
+
 func truncate(s string, length int) string {
 	if len(s) <= length {
 		return s
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) trashCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List or empty the deleted todos",
+	}
+
+	cmd.AddCommand(
+		cli.trashListCommand(),
+		cli.trashEmptyCommand(),
+	)
+
+	return cmd
+}
+
+func (cli *CLI) trashListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the todos in the trash",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			todos, err := cli.todoService.FindAllTodos(cli.context(), domain.TodoQuery{Trashed: true})
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodos(todos, func() {
+				if len(todos) == 0 {
+					fmt.Println("The trash is empty")
+					return
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tDELETED")
+				for _, todo := range todos {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+						todo.ID.String()[:8],
+						truncate(todo.Title, 30),
+						formatStatus(todo),
+						todo.DeletedAt.Local().Format("2006-01-02 15:04"),
+					)
+				}
+				w.Flush()
+			})
+		},
+	}
+}
+
+func (cli *CLI) trashEmptyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete the todos in the trash",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			before, err := parseAgeFlag(cmd, "older-than")
+			if err != nil {
+				return flagError("older-than", err)
+			}
+			if before == nil {
+				now := time.Now()
+				before = &now
+			}
+
+			purged, err := cli.todoService.EmptyTrash(cli.context(), *before)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitValue(struct {
+				Purged int `json:"purged"`
+			}{purged}, func() {
+				fmt.Printf("Permanently deleted %d todo(s)\n", purged)
+			})
+		},
+	}
+
+	cmd.Flags().String("older-than", "", "Only delete todos that have been in the trash this long, e.g. 30d or 2w")
+
+	return cmd
+}
+
+func (cli *CLI) restoreCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore [id]",
+		Short: "Take a TODO and the subtasks deleted with it out of the trash",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cli.context()
+			id, err := cli.todoService.ResolveTrashedTodoID(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			todo, err := cli.todoService.RestoreTodo(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			return cli.emitTodo(todo, func() {
+				fmt.Printf("Todo restored successfully!\n")
+				cli.printTodo(todo)
+			})
+		},
+	}
+}
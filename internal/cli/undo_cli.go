@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) undoCommand() *cobra.Command {
+	return cli.journalCommand(false)
+}
+
+func (cli *CLI) redoCommand() *cobra.Command {
+	return cli.journalCommand(true)
+}
+
+// journalCommand builds undo or, with redo, redo. Both show what they are
+// about to change and ask before changing it.
+func (cli *CLI) journalCommand(redo bool) *cobra.Command {
+	name, short, verb, done := "undo", "Revert your last changes, one command at a time", "Undo", "Undid"
+	if redo {
+		name, short, verb, done = "redo", "Make the changes undo reverted again", "Redo", "Redid"
+	}
+
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, _ := cmd.Flags().GetInt("count")
+			yes, _ := cmd.Flags().GetBool("yes")
+			if n < 1 {
+				return flagError("count", fmt.Errorf("must be at least 1, got %d", n))
+			}
+
+			ctx := cli.context()
+			find, apply := cli.todoService.FindUndoable, cli.todoService.Undo
+			if redo {
+				find, apply = cli.todoService.FindRedoable, cli.todoService.Redo
+			}
+
+			operations, err := find(ctx, n)
+			if err != nil {
+				return err
+			}
+			if len(operations) == 0 {
+				return cli.emitValue(operations, func() {
+					fmt.Printf("Nothing to %s\n", name)
+				})
+			}
+
+			writeOperations(os.Stderr, verb, operations, cli.projectName)
+			if !yes && !confirm(os.Stdin, os.Stderr, "Continue?") {
+				fmt.Fprintln(os.Stderr, "Nothing changed")
+				return nil
+			}
+
+			if err := apply(ctx, operations); err != nil {
+				return err
+			}
+
+			return cli.emitValue(operations, func() {
+				fmt.Printf("%s %d operation(s)\n", done, len(operations))
+			})
+		},
+	}
+
+	cmd.Flags().IntP("count", "n", 1, "Number of commands to "+name)
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// writeOperations lists the changes of each operation under a heading
+// saying what is about to happen to them.
+func writeOperations(w io.Writer, verb string, operations []*domain.Operation, projectName func(*uuid.UUID) string) {
+	fmt.Fprintf(w, "%s %d operation(s):\n", verb, len(operations))
+	for _, operation := range operations {
+		for _, event := range operation.Events {
+			fmt.Fprintf(w, "  %s  %s  %s\n", event.OccurredAt.Local().Format("2006-01-02 15:04:05"), event.Action, event.TodoID.String()[:8])
+			writeChanges(w, "      ", event, projectName)
+		}
+	}
+}
+
+// confirm asks question on w and reports whether the answer read from r is
+// yes. Anything else, including no answer at all, is a no.
+func confirm(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		// Nobody pressed enter, so end the prompt line for them
+		fmt.Fprintln(w)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
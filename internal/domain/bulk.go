@@ -0,0 +1,45 @@
+package domain
+
+import "github.com/google/uuid"
+
+// BulkAction is what a BulkRequest does to each todo it selects.
+type BulkAction string
+
+const (
+	BulkDelete   BulkAction = "delete"
+	BulkComplete BulkAction = "done"
+	BulkToggle   BulkAction = "toggle"
+	BulkTag      BulkAction = "tag"
+	BulkUntag    BulkAction = "untag"
+	BulkMove     BulkAction = "move"
+)
+
+// BulkRequest applies one action to several todos at once, all of them or,
+// if any of them fails, none.
+type BulkRequest struct {
+	Action BulkAction `json:"action"`
+	// IDs are the todos to change. Without any, Query selects them instead.
+	IDs   []uuid.UUID `json:"ids,omitempty"`
+	Query TodoQuery   `json:"-"`
+	// Tags are the tags BulkTag adds and BulkUntag removes.
+	Tags []string `json:"tags,omitempty"`
+	// ProjectID is the project BulkMove moves the todos to; nil takes them
+	// out of their project.
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	// Force lets BulkComplete and BulkToggle complete blocked todos.
+	Force bool `json:"force,omitempty"`
+	// DryRun works out what would change without changing anything.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// BulkResult reports what a BulkRequest changed, or would have changed.
+type BulkResult struct {
+	Action BulkAction `json:"action"`
+	DryRun bool       `json:"dry_run"`
+	// Matched is how many todos the request selected.
+	Matched int `json:"matched"`
+	// Changed are the selected todos the action changed, as they are after
+	// it; todos it had nothing to do to, such as a completed todo marked
+	// done, are left out.
+	Changed []*Todo `json:"changed"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by repositories and services wrap one of these, so callers
+// can tell failures apart with errors.Is whatever storage backend is in use.
+var (
+	// ErrNotFound means a todo, project or other record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrValidation means the input was rejected before anything was stored.
+	ErrValidation = errors.New("invalid input")
+	// ErrConflict means the change clashes with the data already stored, such
+	// as a duplicate name, a dependency cycle or an open blocker.
+	ErrConflict = errors.New("conflict")
+	// ErrStale means the record changed after it was read, so writing the old
+	// copy back would overwrite someone else's change. It matches ErrConflict.
+	ErrStale = fmt.Errorf("%w: changed since it was read", ErrConflict)
+)
+
+// FieldError is a rule one field of a request broke.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of a request that failed validation. It
+// matches ErrValidation.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		problems[i] = field.Field + ": " + field.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(problems, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// AmbiguousIDError is returned when a short ID matches more than one todo.
+type AmbiguousIDError struct {
+	Prefix     string
+	Candidates []*Todo
+}
+
+func (e *AmbiguousIDError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "id %q is ambiguous, it matches:", e.Prefix)
+	for _, todo := range e.Candidates {
+		fmt.Fprintf(&sb, "\n  %s  %s", todo.ID, todo.Title)
+	}
+	return sb.String()
+}
+
+// Unwrap makes an ambiguous ID count as invalid input.
+func (e *AmbiguousIDError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventAction names the kind of change a TodoEvent records.
+type EventAction string
+
+const (
+	EventCreated   EventAction = "created"
+	EventUpdated   EventAction = "updated"
+	EventCompleted EventAction = "completed"
+	EventReopened  EventAction = "reopened"
+	EventDeleted   EventAction = "deleted"
+	EventRestored  EventAction = "restored"
+)
+
+// TodoEvent is one entry in the history of a todo. Events are numbered in
+// the order they were written.
+type TodoEvent struct {
+	ID         int64         `json:"id"`
+	TodoID     uuid.UUID     `json:"todo_id"`
+	Action     EventAction   `json:"action"`
+	Changes    []FieldChange `json:"changes"`
+	Actor      string        `json:"actor"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	// Operation groups the events written by one command.
+	Operation uuid.UUID `json:"operation"`
+	// UndoneAt is when the change was undone, nil unless it is.
+	UndoneAt *time.Time `json:"undone_at,omitempty"`
+}
+
+// Operation is the events one command wrote, oldest first. Undo and redo
+// revert and replay them as a unit.
+type Operation struct {
+	ID     uuid.UUID    `json:"id"`
+	Events []*TodoEvent `json:"events"`
+}
+
+// FieldChange is a field of a todo before and after a change, in the form
+// TodoChanges writes it; nil stands for an empty field.
+type FieldChange struct {
+	Field string  `json:"field"`
+	Old   *string `json:"old"`
+	New   *string `json:"new"`
+}
+
+// NewTodoEvent records action on the todo, by the actor ctx carries, with the
+// fields that differ between before and after. Either may be nil.
+func NewTodoEvent(ctx context.Context, action EventAction, before, after *Todo) *TodoEvent {
+	event := &TodoEvent{
+		Action:     action,
+		Changes:    TodoChanges(before, after),
+		Actor:      ActorFrom(ctx),
+		OccurredAt: time.Now(),
+	}
+	var ok bool
+	if event.Operation, ok = operationFrom(ctx); !ok {
+		event.Operation = uuid.New()
+	}
+	if after != nil {
+		event.TodoID = after.ID
+	} else if before != nil {
+		event.TodoID = before.ID
+	}
+	return event
+}
+
+// eventFields lists the fields TodoChanges compares, with their stored form;
+// an empty string is an empty field. Times are RFC 3339 and IDs are full.
+var eventFields = []struct {
+	name  string
+	value func(*Todo) string
+}{
+	{"title", func(t *Todo) string { return t.Title }},
+	{"description", func(t *Todo) string { return t.Description }},
+	{"completed", func(t *Todo) string {
+		if t.Completed {
+			return "true"
+		}
+		return ""
+	}},
+	{"archived", func(t *Todo) string {
+		if t.ArchivedAt != nil {
+			return "true"
+		}
+		return ""
+	}},
+	{"priority", func(t *Todo) string {
+		if t.Priority == PriorityNone {
+			return ""
+		}
+		return t.Priority.String()
+	}},
+	{"due_date", func(t *Todo) string {
+		if t.DueDate == nil {
+			return ""
+		}
+		return t.DueDate.Format(time.RFC3339)
+	}},
+	{"project_id", func(t *Todo) string { return formatEventID(t.ProjectID) }},
+	{"parent_id", func(t *Todo) string { return formatEventID(t.ParentID) }},
+	{"recurrence", func(t *Todo) string { return t.Recurrence }},
+	{"tags", func(t *Todo) string { return strings.Join(t.Tags, ",") }},
+}
+
+// FieldValue returns the field of todo in the form TodoChanges writes it,
+// nil if it is empty, and false if TodoChanges does not know the field.
+func FieldValue(todo *Todo, field string) (*string, bool) {
+	for _, f := range eventFields {
+		if f.name == field {
+			return eventValue(f.value(todo)), true
+		}
+	}
+	return nil, false
+}
+
+// TodoChanges lists the fields that differ between before and after. A nil
+// todo has only empty fields, so a new todo lists every field it sets.
+func TodoChanges(before, after *Todo) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range eventFields {
+		var from, to string
+		if before != nil {
+			from = field.value(before)
+		}
+		if after != nil {
+			to = field.value(after)
+		}
+		if from != to {
+			changes = append(changes, FieldChange{Field: field.name, Old: eventValue(from), New: eventValue(to)})
+		}
+	}
+	return changes
+}
+
+// BlockersChange records a change to the todos a todo is blocked by, which
+// are not a field of Todo, as the field "blocked_by".
+func BlockersChange(before, after []uuid.UUID) FieldChange {
+	return FieldChange{Field: "blocked_by", Old: EventIDs(before), New: EventIDs(after)}
+}
+
+// EventIDs is the form a set of todo IDs is recorded in: the full IDs,
+// sorted and comma-separated, nil for none.
+func EventIDs(ids []uuid.UUID) *string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	slices.Sort(values)
+	return eventValue(strings.Join(values, ","))
+}
+
+func eventValue(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func formatEventID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx whose changes are recorded as made by
+// actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+type operationKey struct{}
+
+// WithOperation returns a copy of ctx whose changes form a single operation,
+// which undo and redo treat as a unit. Changes made without one are each an
+// operation of their own.
+func WithOperation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operationKey{}, uuid.New())
+}
+
+func operationFrom(ctx context.Context) (uuid.UUID, bool) {
+	operation, ok := ctx.Value(operationKey{}).(uuid.UUID)
+	return operation, ok
+}
+
+// ActorFrom returns the actor recorded with ctx, or "unknown".
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "unknown"
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority orders todos by urgency. The zero value means no priority was set,
+// and higher values are more urgent.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityCritical
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "critical"}
+
+func ParsePriority(s string) (Priority, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, n := range priorityNames {
+		if n == name {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("invalid priority %q (expected one of: %s)", s, strings.Join(priorityNames, ", "))
+}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityCritical {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TodoQuery narrows, orders and pages the todos returned by
+// TodoRepository.FindAll. The zero value returns every todo that is neither
+// archived nor in the trash, newest first.
+type TodoQuery struct {
+	// Completed keeps only completed todos when true and only pending ones
+	// when false.
+	Completed *bool
+	// Text keeps only todos whose title or description contains it, ignoring
+	// case.
+	Text string
+	// DueBefore and DueAfter keep only todos due strictly within the range;
+	// todos without a due date never match.
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// CreatedBefore and CreatedAfter keep only todos created strictly within
+	// the range.
+	CreatedBefore *time.Time
+	CreatedAfter  *time.Time
+	// CompletedBefore and CompletedAfter keep only todos completed strictly
+	// within the range; pending todos never match.
+	CompletedBefore *time.Time
+	CompletedAfter  *time.Time
+	// Tags keeps only todos carrying every one of these tags.
+	Tags []string
+	// ProjectID keeps only todos in this project.
+	ProjectID *uuid.UUID
+	// ParentID keeps only the direct subtasks of this todo.
+	ParentID *uuid.UUID
+	// Blocked keeps only todos with open blockers when true and only those
+	// without when false.
+	Blocked *bool
+	// Blocking keeps only the todos this todo is blocked by.
+	Blocking *uuid.UUID
+	// Archived lists the archived todos instead of the others, and
+	// IncludeArchived lists them alongside the others.
+	Archived        bool
+	IncludeArchived bool
+	// Trashed lists the todos in the trash instead of the live ones,
+	// archived or not.
+	Trashed bool
+	// IDPrefix keeps only todos whose ID starts with this lower-case prefix.
+	IDPrefix string
+	// IDs keeps only the todos with one of these IDs.
+	IDs  []uuid.UUID
+	Sort []SortKey
+	// Limit caps the number of todos returned; zero means no limit.
+	Limit int
+	// Offset skips this many todos, once sorted. It only applies together
+	// with Limit.
+	Offset int
+}
+
+type SortField string
+
+const (
+	// SortPriority puts the most urgent todos first.
+	SortPriority SortField = "priority"
+	// SortDue puts the earliest due dates first and todos without one last.
+	SortDue SortField = "due"
+	// SortCreated puts the newest todos first.
+	SortCreated SortField = "created"
+)
+
+type SortKey struct {
+	Field SortField
+	// Reverse flips the field's natural direction.
+	Reverse bool
+}
+
+// ParseSort reads a comma-separated list of sort fields such as
+// "priority,due,created". A leading "-" reverses a field, e.g. "-created"
+// lists the oldest todos first.
+func ParseSort(s string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		key := SortKey{Field: SortField(strings.TrimPrefix(part, "-")), Reverse: strings.HasPrefix(part, "-")}
+		switch key.Field {
+		case SortPriority, SortDue, SortCreated:
+		default:
+			return nil, fmt.Errorf("invalid sort field %q (expected priority, due or created)", key.Field)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -2,14 +2,91 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// UnitOfWork groups repository calls into one transaction, so that a write
+// based on a read cannot interleave with someone else's. Each repository is
+// a UnitOfWork of itself: R is the repository fn gets, bound to the
+// transaction.
+type UnitOfWork[R any] interface {
+	// Atomically runs fn with a repository whose calls are committed
+	// together once fn returns, or not at all if it fails. Called on such a
+	// repository, it joins the transaction that is already open. When a
+	// concurrent transaction changed what fn read, fn may run again from
+	// the start, so it must not carry state from one run to the next.
+	Atomically(ctx context.Context, fn func(repo R) error) error
+}
+
 type TodoRepository interface {
-	FindAll(ctx context.Context) ([]*Todo, error)
+	UnitOfWork[TodoRepository]
+	FindAll(ctx context.Context, query TodoQuery) ([]*Todo, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*Todo, error)
+	// ResolveID turns a full ID, or an ID prefix unique among the todos scope
+	// matches, into the todo's ID.
+	ResolveID(ctx context.Context, ref string, scope TodoQuery) (uuid.UUID, error)
 	Create(ctx context.Context, todo *Todo) error
-	Update(ctx context.Context, todo *Todo) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Update writes the columns patch sets and nothing else. It fails with
+	// ErrStale when the todo is no longer at patch.Version.
+	Update(ctx context.Context, id uuid.UUID, patch TodoPatch) error
+	// SetCompleted is Update that also marks the todo done, stamping
+	// completed_at with patch.UpdatedAt, or pending again, clearing it.
+	SetCompleted(ctx context.Context, id uuid.UUID, completed bool, patch TodoPatch) error
+	// UpdateMany is Update for several todos at once, each based on the
+	// version it has in todos. It fails with ErrStale, writing nothing, when
+	// any of them has changed since.
+	UpdateMany(ctx context.Context, todos []*Todo, patch TodoPatch) error
+	// SetCompletedMany is SetCompleted for several todos at once, as
+	// UpdateMany is for Update.
+	SetCompletedMany(ctx context.Context, todos []*Todo, completed bool, patch TodoPatch) error
+	// Delete moves the todo and its subtasks to the trash, stamping them with
+	// deletedAt.
+	Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+	// DeleteMany is Delete for several todos at once. Like UpdateMany it
+	// fails with ErrStale when any of them has changed since.
+	DeleteMany(ctx context.Context, todos []*Todo, deletedAt time.Time) error
+	// Restore takes the todo out of the trash, together with the subtasks
+	// that were deleted along with it.
+	Restore(ctx context.Context, id uuid.UUID) error
+	// Archive stamps archivedAt on every todo query matches that is not
+	// archived yet, returning how many there were.
+	Archive(ctx context.Context, query TodoQuery, archivedAt time.Time) (int, error)
+	// Unarchive puts an archived todo back among the others.
+	Unarchive(ctx context.Context, id uuid.UUID) error
+	// Purge permanently removes the todos moved to the trash before before,
+	// returning how many there were.
+	Purge(ctx context.Context, before time.Time) (int, error)
+	AddTags(ctx context.Context, id uuid.UUID, tags []string) error
+	RemoveTags(ctx context.Context, id uuid.UUID, tags []string) error
+	// AddTagsMany and RemoveTagsMany are AddTags and RemoveTags for several
+	// todos at once.
+	AddTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error
+	RemoveTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error
+	FindAllTags(ctx context.Context) ([]*TagCount, error)
+	AddBlocker(ctx context.Context, id, blockerID uuid.UUID) error
+	RemoveBlocker(ctx context.Context, id, blockerID uuid.UUID) error
+	// AddEvent appends event to the history of its todo, filling in its ID.
+	AddEvent(ctx context.Context, event *TodoEvent) error
+	// FindEvents returns the history of the todo, oldest first.
+	FindEvents(ctx context.Context, id uuid.UUID) ([]*TodoEvent, error)
+	// FindUndoable returns the last limit operations of actor that were not
+	// undone, newest first.
+	FindUndoable(ctx context.Context, actor string, limit int) ([]*Operation, error)
+	// FindRedoable returns up to limit operations of actor undone since their
+	// last other change, the last one undone first.
+	FindRedoable(ctx context.Context, actor string, limit int) ([]*Operation, error)
+	// SetUndone marks the events of the operation as undone at undoneAt, or
+	// as in effect again when it is nil.
+	SetUndone(ctx context.Context, operation uuid.UUID, undoneAt *time.Time) error
+}
+
+type ProjectRepository interface {
+	UnitOfWork[ProjectRepository]
+	FindAll(ctx context.Context, includeArchived bool) ([]*Project, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*Project, error)
+	FindByName(ctx context.Context, name string) (*Project, error)
+	Create(ctx context.Context, project *Project) error
+	Update(ctx context.Context, project *Project) error
 }
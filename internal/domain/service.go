@@ -2,15 +2,76 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type TodoService interface {
-	FindAllTodos(ctx context.Context) ([]*Todo, error)
+	FindAllTodos(ctx context.Context, query TodoQuery) ([]*Todo, error)
 	FindTodoByID(ctx context.Context, id uuid.UUID) (*Todo, error)
+	ResolveTodoID(ctx context.Context, ref string) (uuid.UUID, error)
+	// ResolveTrashedTodoID is ResolveTodoID for the todos in the trash.
+	ResolveTrashedTodoID(ctx context.Context, ref string) (uuid.UUID, error)
 	CreateTodo(ctx context.Context, request CreateTodoRequest) (*Todo, error)
 	UpdateTodo(ctx context.Context, request UpdateTodoRequest) (*Todo, error)
+	// DeleteTodo moves the todo and its subtasks to the trash.
 	DeleteTodo(ctx context.Context, id uuid.UUID) error
-	ToggleTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
+	// RestoreTodo takes a todo out of the trash; its parent has to be
+	// restored first.
+	RestoreTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
+	// EmptyTrash permanently removes the todos moved to the trash before
+	// before, returning how many there were.
+	EmptyTrash(ctx context.Context, before time.Time) (int, error)
+	// ArchiveTodo hides the todo from the default listing; an archived todo
+	// is returned as it is.
+	ArchiveTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
+	// UnarchiveTodo lists the todo by default again; a todo that is not
+	// archived is returned as it is.
+	UnarchiveTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
+	// ArchiveCompleted archives the todos completed before before, returning
+	// how many there were.
+	ArchiveCompleted(ctx context.Context, before time.Time) (int, error)
+	ToggleTodo(ctx context.Context, id uuid.UUID, opts ToggleOptions) (*Todo, error)
+	// CompleteTodo marks the todo done; a todo that already is done is
+	// returned as it is.
+	CompleteTodo(ctx context.Context, id uuid.UUID, opts ToggleOptions) (*Todo, error)
+	// ReopenTodo marks the todo pending; a todo that already is pending is
+	// returned as it is.
+	ReopenTodo(ctx context.Context, id uuid.UUID) (*Todo, error)
+	FindSubtasks(ctx context.Context, id uuid.UUID) ([]*Todo, error)
+	AddTags(ctx context.Context, id uuid.UUID, tags []string) (*Todo, error)
+	RemoveTags(ctx context.Context, id uuid.UUID, tags []string) (*Todo, error)
+	FindAllTags(ctx context.Context) ([]*TagCount, error)
+	BlockTodo(ctx context.Context, id, blockerID uuid.UUID) (*Todo, error)
+	UnblockTodo(ctx context.Context, id, blockerID uuid.UUID) (*Todo, error)
+	FindBlockers(ctx context.Context, id uuid.UUID) ([]*Todo, error)
+	// FindHistory returns the changes made to the todo, oldest first.
+	FindHistory(ctx context.Context, id uuid.UUID) ([]*TodoEvent, error)
+	// BulkUpdate applies request to every todo it selects in a single
+	// transaction. It fails with ErrNotFound when any of request.IDs does
+	// not exist.
+	BulkUpdate(ctx context.Context, request BulkRequest) (*BulkResult, error)
+	// FindUndoable returns the last n operations of the actor ctx carries
+	// that Undo can revert, newest first.
+	FindUndoable(ctx context.Context, n int) ([]*Operation, error)
+	// FindRedoable returns up to n operations of the actor ctx carries that
+	// Redo can replay, in the order it replays them.
+	FindRedoable(ctx context.Context, n int) ([]*Operation, error)
+	// Undo reverts operations in the order given, all or none of them. It
+	// fails with ErrConflict when a todo was changed since.
+	Undo(ctx context.Context, operations []*Operation) error
+	// Redo replays operations Undo reverted, in the order given.
+	Redo(ctx context.Context, operations []*Operation) error
+	// CompletionStats reports the throughput and lead time of the todos
+	// completed after since.
+	CompletionStats(ctx context.Context, since time.Time) (*CompletionStats, error)
+}
+
+type ProjectService interface {
+	FindAllProjects(ctx context.Context, includeArchived bool) ([]*Project, error)
+	FindProjectByName(ctx context.Context, name string) (*Project, error)
+	CreateProject(ctx context.Context, request CreateProjectRequest) (*Project, error)
+	RenameProject(ctx context.Context, id uuid.UUID, name string) (*Project, error)
+	ArchiveProject(ctx context.Context, id uuid.UUID) (*Project, error)
 }
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CompletionStats summarises the todos completed since a point in time.
+type CompletionStats struct {
+	Since     time.Time `json:"since"`
+	Completed int       `json:"completed"`
+	// Days and Weeks count completions per local calendar day and per week
+	// starting on Monday, oldest first. Periods without completions are
+	// included with a zero count.
+	Days  []PeriodCount `json:"days"`
+	Weeks []PeriodCount `json:"weeks"`
+	// MedianLeadTime is the median time from creating a todo to completing
+	// it, zero when nothing was completed.
+	MedianLeadTime Duration `json:"median_lead_time"`
+}
+
+// PeriodCount is the number of todos completed in the day or week starting
+// at Start.
+type PeriodCount struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// Duration is a time.Duration that reads like "26h30m0s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,7 @@
+package domain
+
+// TagCount is a tag together with the number of todos carrying it.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,17 +12,157 @@ type Todo struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// CompletedAt is when the todo was last completed, nil while pending.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Priority    Priority   `json:"priority"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Subtasks    Progress   `json:"subtasks"`
+	// OpenBlockers counts the unfinished todos this one is blocked by.
+	OpenBlockers int `json:"open_blockers"`
+	// Recurrence is the RRULE the todo repeats on, empty if it does not.
+	Recurrence string    `json:"recurrence,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// Version counts the writes to the todo. An update based on an older
+	// version is rejected with ErrStale instead of overwriting newer data.
+	Version int `json:"version"`
+	// DeletedAt is when the todo was moved to the trash, nil for live todos.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// ArchivedAt is when the todo was archived, nil unless it is.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// NextOccurrence is set by TodoService.ToggleTodo when completing a
+	// recurring todo spawned its next occurrence. It is never stored.
+	NextOccurrence *Todo `json:"next_occurrence,omitempty"`
+}
+
+// IsBlocked reports whether the todo is still pending behind open blockers.
+func (t *Todo) IsBlocked() bool {
+	return !t.Completed && t.OpenBlockers > 0
+}
+
+// IsOverdue reports whether the todo is still pending past its due date.
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueDate != nil && t.DueDate.Before(now)
+}
+
+// Progress counts a todo's direct subtasks and how many of them are done.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d subtasks done", p.Done, p.Total)
 }
 
 type CreateTodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    Priority   `json:"priority"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	// Recurrence is a rule understood by recurrence.Parse, e.g. "weekly".
+	Recurrence string `json:"recurrence,omitempty"`
 }
 
+// UpdateTodoRequest changes only the fields it sets; nil fields are left as
+// they are.
 type UpdateTodoRequest struct {
 	ID          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
+	Title       *string   `json:"title,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	// ClearDescription empties the description; Description and
+	// AppendDescription are ignored when set.
+	ClearDescription bool `json:"clear_description,omitempty"`
+	// AppendDescription is added to the description on a new line, after
+	// Description if both are given.
+	AppendDescription *string    `json:"append_description,omitempty"`
+	Priority          *Priority  `json:"priority,omitempty"`
+	DueDate           *time.Time `json:"due_date,omitempty"`
+	// ClearDueDate removes the due date; DueDate is ignored when set.
+	ClearDueDate bool       `json:"clear_due_date,omitempty"`
+	ProjectID    *uuid.UUID `json:"project_id,omitempty"`
+	// ClearProject removes the todo from its project; ProjectID is ignored
+	// when set.
+	ClearProject bool       `json:"clear_project,omitempty"`
+	ParentID     *uuid.UUID `json:"parent_id,omitempty"`
+	// ClearParent turns a subtask back into a top-level todo; ParentID is
+	// ignored when set.
+	ClearParent bool    `json:"clear_parent,omitempty"`
+	Recurrence  *string `json:"recurrence,omitempty"`
+	// ClearRecurrence stops the todo from repeating; Recurrence is ignored
+	// when set.
+	ClearRecurrence bool `json:"clear_recurrence,omitempty"`
+	// Version is the version of the todo the caller last saw; the update
+	// fails with ErrStale if it has changed since. Zero means the version
+	// UpdateTodo reads itself.
+	Version int `json:"version,omitempty"`
+}
+
+// TodoPatch is the set of columns TodoRepository.Update writes. Nil fields
+// and unset Clear flags leave their column untouched; UpdatedAt is always
+// written and the version is bumped.
+type TodoPatch struct {
+	Title        *string
+	Description  *string
+	Priority     *Priority
+	DueDate      *time.Time
+	ClearDueDate bool
+	ProjectID    *uuid.UUID
+	ClearProject bool
+	ParentID     *uuid.UUID
+	ClearParent  bool
+	Recurrence   *string
+	UpdatedAt    time.Time
+	// Version is the version the patch is based on. Update fails with
+	// ErrStale when the stored todo is at any other version.
+	Version int
+}
+
+// Apply copies the patched fields onto todo.
+func (p TodoPatch) Apply(todo *Todo) {
+	if p.Title != nil {
+		todo.Title = *p.Title
+	}
+	if p.Description != nil {
+		todo.Description = *p.Description
+	}
+	if p.Priority != nil {
+		todo.Priority = *p.Priority
+	}
+	if p.ClearDueDate {
+		todo.DueDate = nil
+	} else if p.DueDate != nil {
+		todo.DueDate = p.DueDate
+	}
+	if p.ClearProject {
+		todo.ProjectID = nil
+	} else if p.ProjectID != nil {
+		todo.ProjectID = p.ProjectID
+	}
+	if p.ClearParent {
+		todo.ParentID = nil
+	} else if p.ParentID != nil {
+		todo.ParentID = p.ParentID
+	}
+	if p.Recurrence != nil {
+		todo.Recurrence = *p.Recurrence
+	}
+	todo.UpdatedAt = p.UpdatedAt
+	todo.Version = p.Version + 1
+}
+
+// ToggleOptions tunes TodoService.ToggleTodo and TodoService.CompleteTodo.
+type ToggleOptions struct {
+	// Cascade completes every subtask along with the todo. Reopening a todo
+	// never touches its subtasks.
+	Cascade bool
+	// Force completes the todo, and with Cascade its subtasks, even though
+	// they are blocked by open todos.
+	Force bool
 }
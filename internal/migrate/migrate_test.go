@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+var testMigrations = fstest.MapFS{
+	"sql/001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+	"sql/001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+	"sql/002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+	"sql/002_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+	"sql/README.md":             {Data: []byte("ignored")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := Load(testMigrations, "sql")
+	require.NoError(t, err)
+
+	return New(NewSQLiteDriver(db), migrations), db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	require.NoError(t, err)
+	return count == 1
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testMigrations, "sql")
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_a", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE a;", migrations[0].Down)
+}
+
+func TestLoad_MissingUp(t *testing.T) {
+	_, err := Load(fstest.MapFS{
+		"sql/001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+	}, "sql")
+	assert.Error(t, err)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	done, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, done, 2)
+	assert.True(t, tableExists(t, db, "a"))
+	assert.True(t, tableExists(t, db, "b"))
+
+	// Nothing left to apply
+	done, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	done, err := migrator.Down(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, int64(2), done[0].Version)
+	assert.True(t, tableExists(t, db, "a"))
+	assert.False(t, tableExists(t, db, "b"))
+}
+
+func TestMigrator_Status(t *testing.T) {
+	migrator, _ := newTestMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	_, err = migrator.Down(ctx, 1)
+	require.NoError(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[0].AppliedAt.IsZero())
+	assert.False(t, statuses[1].Applied)
+}
+
+func TestMigrator_Up_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+	migrations, err := Load(testMigrations, "sql")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	applied := make([]int, 2)
+	errs := make([]error, 2)
+	for i := range 2 {
+		db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := New(NewSQLiteDriver(db), migrations).Up(ctx)
+			applied[i], errs[i] = len(done), err
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	// Each migration is applied once, by whichever runner took the lock first
+	assert.Equal(t, 2, applied[0]+applied[1])
+}
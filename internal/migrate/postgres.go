@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresLockKey identifies this application's advisory lock.
+const postgresLockKey = 7_265_130_771
+
+// PostgresDriver holds a session-level advisory lock on a dedicated pool
+// connection while migrating, so concurrent runners wait for each other.
+type PostgresDriver struct {
+	pool *pgxpool.Pool
+	conn *pgxpool.Conn
+}
+
+func NewPostgresDriver(pool *pgxpool.Pool) *PostgresDriver {
+	return &PostgresDriver{pool: pool}
+}
+
+func (d *PostgresDriver) Lock(ctx context.Context) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", postgresLockKey); err != nil {
+		conn.Release()
+		return err
+	}
+	d.conn = conn
+
+	_, err = conn.Exec(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version BIGINT PRIMARY KEY,
+				name TEXT NOT NULL,
+				applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+	`)
+	if err != nil {
+		// The runner only unlocks after Lock succeeds
+		d.Unlock(ctx)
+		return err
+	}
+	return nil
+}
+
+func (d *PostgresDriver) Unlock(ctx context.Context) error {
+	if d.conn == nil {
+		return errors.New("migration lock is not held")
+	}
+	defer func() {
+		d.conn.Release()
+		d.conn = nil
+	}()
+
+	_, err := d.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", postgresLockKey)
+	return err
+}
+
+func (d *PostgresDriver) Applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func (d *PostgresDriver) Apply(ctx context.Context, m Migration, script string, up bool) error {
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+
+	if up {
+		_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+	} else {
+		_, err = tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SQLiteDriver takes the database's write lock with BEGIN IMMEDIATE on a
+// dedicated connection and holds it until Unlock, so a concurrent runner
+// waits instead of applying the same migration and failing halfway, on a
+// duplicate column for instance. Each migration runs in a savepoint of that
+// transaction, so the ones before a failing migration are kept.
+type SQLiteDriver struct {
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+func NewSQLiteDriver(db *sql.DB) *SQLiteDriver {
+	return &SQLiteDriver{db: db}
+}
+
+func (d *SQLiteDriver) Lock(ctx context.Context) error {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		conn.Close()
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INTEGER PRIMARY KEY,
+				name TEXT NOT NULL,
+				applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+	`)
+	if err != nil {
+		conn.ExecContext(ctx, "ROLLBACK")
+		conn.Close()
+		return err
+	}
+	d.conn = conn
+
+	return nil
+}
+
+func (d *SQLiteDriver) Unlock(ctx context.Context) error {
+	if d.conn == nil {
+		return errors.New("migration lock is not held")
+	}
+	defer func() {
+		d.conn.Close()
+		d.conn = nil
+	}()
+
+	_, err := d.conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+func (d *SQLiteDriver) Applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt string
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", appliedAt, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = t.Local()
+	}
+
+	return applied, rows.Err()
+}
+
+func (d *SQLiteDriver) Apply(ctx context.Context, m Migration, script string, up bool) error {
+	if _, err := d.conn.ExecContext(ctx, "SAVEPOINT migration"); err != nil {
+		return err
+	}
+
+	if err := d.apply(ctx, m, script, up); err != nil {
+		d.conn.ExecContext(ctx, "ROLLBACK TO migration")
+		d.conn.ExecContext(ctx, "RELEASE migration")
+		return err
+	}
+
+	_, err := d.conn.ExecContext(ctx, "RELEASE migration")
+	return err
+}
+
+func (d *SQLiteDriver) apply(ctx context.Context, m Migration, script string, up bool) error {
+	if _, err := d.conn.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	var err error
+	if up {
+		_, err = d.conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name)
+	} else {
+		_, err = d.conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package naturaldate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Inputs that name a day without a time of day resolve to the end of that
+// day, so "due tomorrow" stays on time until tomorrow is over.
+const (
+	endOfDayHour   = 23
+	endOfDayMinute = 59
+)
+
+var (
+	relative  = regexp.MustCompile(`^([+-])(\d+)([mhdw])$`)
+	timeOfDay = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var absoluteLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// Parse resolves input relative to now. It understands:
+//
+//	now, today, tomorrow, yesterday   optionally followed by HH:MM
+//	mon ... sunday                    the next such day (today included), optionally followed by HH:MM
+//	+3d, -2w, +4h, +30m               offsets from now in minutes, hours, days or weeks
+//	2025-01-31, 2025-01-31 17:00      absolute dates, also RFC 3339
+func Parse(input string, now time.Time) (time.Time, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	if match := relative.FindStringSubmatch(input); match != nil {
+		return parseRelative(match, now), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", input, now.Location()); err == nil {
+		return endOfDay(t), nil
+	}
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, input, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	day, clock, _ := strings.Cut(input, " ")
+	if day == "now" && clock == "" {
+		return now, nil
+	}
+
+	date, ok := resolveDay(day, now)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unrecognised date %q", input)
+	}
+	if clock == "" {
+		return endOfDay(date), nil
+	}
+
+	hour, minute, err := parseClock(strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised date %q: %v", input, err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, now.Location()), nil
+}
+
+func parseRelative(match []string, now time.Time) time.Time {
+	n, _ := strconv.Atoi(match[2])
+	if match[1] == "-" {
+		n = -n
+	}
+
+	switch match[3] {
+	case "m":
+		return now.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return now.Add(time.Duration(n) * time.Hour)
+	case "w":
+		return now.AddDate(0, 0, 7*n)
+	default:
+		return now.AddDate(0, 0, n)
+	}
+}
+
+func resolveDay(day string, now time.Time) (time.Time, bool) {
+	switch day {
+	case "today":
+		return now, true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), true
+	case "yesterday":
+		return now.AddDate(0, 0, -1), true
+	}
+
+	weekday, ok := weekdays[day]
+	if !ok {
+		return time.Time{}, false
+	}
+	offset := (int(weekday) - int(now.Weekday()) + 7) % 7
+	return now.AddDate(0, 0, offset), true
+}
+
+func parseClock(clock string) (int, int, error) {
+	match := timeOfDay.FindStringSubmatch(clock)
+	if match == nil {
+		return 0, 0, fmt.Errorf("expected HH:MM, got %q", clock)
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q", clock)
+	}
+	return hour, minute, nil
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), endOfDayHour, endOfDayMinute, 0, 0, t.Location())
+}
@@ -0,0 +1,45 @@
+package naturaldate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, time.March, 12, 10, 30, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"now":              now,
+		"today":            time.Date(2025, time.March, 12, 23, 59, 0, 0, time.UTC),
+		"Tomorrow":         time.Date(2025, time.March, 13, 23, 59, 0, 0, time.UTC),
+		"tomorrow 9:15":    time.Date(2025, time.March, 13, 9, 15, 0, 0, time.UTC),
+		"yesterday":        time.Date(2025, time.March, 11, 23, 59, 0, 0, time.UTC),
+		"fri 17:00":        time.Date(2025, time.March, 14, 17, 0, 0, 0, time.UTC),
+		"wednesday":        time.Date(2025, time.March, 12, 23, 59, 0, 0, time.UTC),
+		"tue":              time.Date(2025, time.March, 18, 23, 59, 0, 0, time.UTC),
+		"+3d":              time.Date(2025, time.March, 15, 10, 30, 0, 0, time.UTC),
+		"-1w":              time.Date(2025, time.March, 5, 10, 30, 0, 0, time.UTC),
+		"+4h":              time.Date(2025, time.March, 12, 14, 30, 0, 0, time.UTC),
+		"+45m":             time.Date(2025, time.March, 12, 11, 15, 0, 0, time.UTC),
+		"2025-04-01":       time.Date(2025, time.April, 1, 23, 59, 0, 0, time.UTC),
+		"2025-04-01 08:00": time.Date(2025, time.April, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	for input, expected := range tests {
+		result, err := Parse(input, now)
+		assert.NoError(t, err, input)
+		assert.True(t, expected.Equal(result), "%s: expected %s, got %s", input, expected, result)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	now := time.Now()
+
+	for _, input := range []string{"", "someday", "fri 25:00", "tomorrow noon", "+3y"} {
+		_, err := Parse(input, now)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,216 @@
+package recurrence
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Rule is the subset of an RFC 5545 RRULE that todos can repeat on: a
+// frequency, an interval and, for weekly rules, the days of the week.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// ByDay restricts weekly rules to these days; empty means the weekday of
+	// the previous occurrence.
+	ByDay []time.Weekday
+}
+
+var every = regexp.MustCompile(`^every (\d+) (day|week|month|year)s?$`)
+
+var shorthands = map[string]Rule{
+	"daily":    {Freq: Daily, Interval: 1},
+	"weekly":   {Freq: Weekly, Interval: 1},
+	"monthly":  {Freq: Monthly, Interval: 1},
+	"yearly":   {Freq: Yearly, Interval: 1},
+	"weekdays": {Freq: Weekly, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}},
+}
+
+var unitFrequencies = map[string]Frequency{
+	"day":   Daily,
+	"week":  Weekly,
+	"month": Monthly,
+	"year":  Yearly,
+}
+
+var dayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Parse reads a recurrence rule. It understands:
+//
+//	daily, weekly, monthly, yearly, weekdays
+//	every 2 weeks, every 3 days          any interval of days, weeks, months or years
+//	FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH   RRULE with FREQ, INTERVAL and BYDAY, optionally prefixed with RRULE:
+func Parse(input string) (Rule, error) {
+	input = strings.TrimSpace(input)
+	lower := strings.ToLower(input)
+	if lower == "" {
+		return Rule{}, fmt.Errorf("empty recurrence rule")
+	}
+
+	if rule, ok := shorthands[lower]; ok {
+		return rule, nil
+	}
+
+	if match := every.FindStringSubmatch(lower); match != nil {
+		interval, _ := strconv.Atoi(match[1])
+		if interval < 1 {
+			return Rule{}, fmt.Errorf("invalid recurrence %q: interval must be at least 1", input)
+		}
+		return Rule{Freq: unitFrequencies[match[2]], Interval: interval}, nil
+	}
+
+	return parseRRule(input)
+}
+
+func parseRRule(input string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	body := strings.ToUpper(input)
+	body = strings.TrimPrefix(body, "RRULE:")
+	for _, part := range strings.Split(body, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("unrecognised recurrence %q", input)
+		}
+
+		switch key {
+		case "FREQ":
+			switch freq := Frequency(value); freq {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = freq
+			default:
+				return Rule{}, fmt.Errorf("invalid recurrence %q: unsupported FREQ %s", input, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("invalid recurrence %q: INTERVAL must be a positive number", input)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := dayCodes[code]
+				if !ok {
+					return Rule{}, fmt.Errorf("invalid recurrence %q: unknown day %s", input, code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return Rule{}, fmt.Errorf("invalid recurrence %q: unsupported %s", input, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("invalid recurrence %q: FREQ is required", input)
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return Rule{}, fmt.Errorf("invalid recurrence %q: BYDAY is only supported with FREQ=WEEKLY", input)
+	}
+
+	return rule, nil
+}
+
+// String formats the rule as an RRULE, which Parse reads back unchanged.
+func (r Rule) String() string {
+	s := "FREQ=" + string(r.Freq)
+	if r.Interval > 1 {
+		s += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		s += ";BYDAY=" + strings.Join(codes, ",")
+	}
+	return s
+}
+
+// Describe phrases the rule for people, e.g. "every 2 weeks on mon,thu".
+func (r Rule) Describe() string {
+	units := map[Frequency]string{Daily: "day", Weekly: "week", Monthly: "month", Yearly: "year"}
+
+	s := strings.ToLower(string(r.Freq))
+	if r.Interval > 1 {
+		s = fmt.Sprintf("every %d %ss", r.Interval, units[r.Freq])
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToLower(day.String()[:3])
+		}
+		s += " on " + strings.Join(days, ",")
+	}
+	return s
+}
+
+// Next returns the first occurrence strictly after t, keeping its time of day.
+// Monthly and yearly rules that land on a day the month does not have fall
+// back to the month's last day.
+func (r Rule) Next(t time.Time) time.Time {
+	interval := max(r.Interval, 1)
+
+	switch r.Freq {
+	case Daily:
+		return t.AddDate(0, 0, interval)
+	case Monthly:
+		return addMonths(t, interval)
+	case Yearly:
+		return addMonths(t, 12*interval)
+	}
+
+	if len(r.ByDay) == 0 {
+		return t.AddDate(0, 0, 7*interval)
+	}
+
+	// Weeks start on Monday, as in RFC 5545, and only every interval-th week
+	// counting from t's own week is eligible.
+	start := startOfWeek(t)
+	for offset := 1; offset <= 7*interval; offset++ {
+		candidate := t.AddDate(0, 0, offset)
+		week := int(startOfWeek(candidate).Sub(start).Hours()/24+0.5) / 7
+		if week%interval == 0 && r.onDay(candidate.Weekday()) {
+			return candidate
+		}
+	}
+	return t.AddDate(0, 0, 7*interval)
+}
+
+func (r Rule) onDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
@@ -0,0 +1,94 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"daily":                           "FREQ=DAILY",
+		"Weekly":                          "FREQ=WEEKLY",
+		"weekdays":                        "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"every 2 weeks":                   "FREQ=WEEKLY;INTERVAL=2",
+		"every 1 month":                   "FREQ=MONTHLY",
+		"FREQ=YEARLY":                     "FREQ=YEARLY",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TH":   "FREQ=WEEKLY;BYDAY=MO,TH",
+		"freq=daily;interval=3":           "FREQ=DAILY;INTERVAL=3",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=FR": "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR",
+	}
+
+	for input, expected := range tests {
+		rule, err := Parse(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, rule.String(), input)
+
+		// The canonical form parses back to the same rule
+		again, err := Parse(rule.String())
+		assert.NoError(t, err, input)
+		assert.Equal(t, rule, again, input)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"sometimes",
+		"every 0 days",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;COUNT=5",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=MO",
+	}
+
+	for _, input := range inputs {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRule_Next(t *testing.T) {
+	// Wednesday
+	wed := time.Date(2025, time.March, 12, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"daily", wed, time.Date(2025, time.March, 13, 9, 0, 0, 0, time.UTC)},
+		{"every 3 days", wed, time.Date(2025, time.March, 15, 9, 0, 0, 0, time.UTC)},
+		{"weekly", wed, time.Date(2025, time.March, 19, 9, 0, 0, 0, time.UTC)},
+		{"weekdays", time.Date(2025, time.March, 14, 9, 0, 0, 0, time.UTC), time.Date(2025, time.March, 17, 9, 0, 0, 0, time.UTC)},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", wed, time.Date(2025, time.March, 13, 9, 0, 0, 0, time.UTC)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", time.Date(2025, time.March, 13, 9, 0, 0, 0, time.UTC), time.Date(2025, time.March, 24, 9, 0, 0, 0, time.UTC)},
+		{"monthly", wed, time.Date(2025, time.April, 12, 9, 0, 0, 0, time.UTC)},
+		{"monthly", time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC), time.Date(2025, time.February, 28, 9, 0, 0, 0, time.UTC)},
+		{"yearly", time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), time.Date(2025, time.February, 28, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.rule)
+		assert.NoError(t, err, tt.rule)
+
+		next := rule.Next(tt.from)
+		assert.True(t, tt.expected.Equal(next), "%s from %s: expected %s, got %s", tt.rule, tt.from, tt.expected, next)
+	}
+}
+
+func TestRule_Describe(t *testing.T) {
+	tests := map[string]string{
+		"daily":                              "daily",
+		"every 2 weeks":                      "every 2 weeks",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH": "every 2 weeks on mon,thu",
+	}
+
+	for input, expected := range tests {
+		rule, err := Parse(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, rule.Describe(), input)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// pgError translates a Postgres error about subject, e.g. "todo <id>", into
+// the matching domain error. Errors without a domain meaning pass through.
+func pgError(err error, subject string) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("%s: %w", subject, domain.ErrNotFound)
+	case errors.As(err, &pgErr):
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return fmt.Errorf("%s: %w: already exists", subject, domain.ErrConflict)
+		case "23503": // foreign_key_violation
+			return fmt.Errorf("%s: %w: refers to a record that does not exist", subject, domain.ErrConflict)
+		// not_null_violation, check_violation, string_data_right_truncation
+		case "23502", "23514", "22001":
+			return fmt.Errorf("%s: %w: %s", subject, domain.ErrValidation, pgErr.Message)
+		}
+	}
+	return err
+}
+
+// serializationFailure reports whether err aborted a transaction that
+// raced a concurrent one, so running it again may succeed.
+func serializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// serialization_failure, deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// staleTodoError reports that a patch was based on an older version of todo
+// id than the stored one, current.
+func staleTodoError(id uuid.UUID, current int) error {
+	return fmt.Errorf("todo %s: %w (now at version %d)", id, domain.ErrStale, current)
+}
+
+// staleBatchError reports that only matched of a batch of total todos were
+// still at the version the batch was based on.
+func staleBatchError(matched int64, total int) error {
+	return fmt.Errorf("%w: %d of %d todos changed since they were read", domain.ErrStale, int64(total)-matched, total)
+}
+
+// sqliteError is pgError for SQLite.
+func sqliteError(err error, subject string) error {
+	var sqliteErr *sqlitedriver.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%s: %w", subject, domain.ErrNotFound)
+	case errors.As(err, &sqliteErr):
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return fmt.Errorf("%s: %w: already exists", subject, domain.ErrConflict)
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return fmt.Errorf("%s: %w: refers to a record that does not exist", subject, domain.ErrConflict)
+		case sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_CHECK:
+			return fmt.Errorf("%s: %w: %s", subject, domain.ErrValidation, sqliteErr)
+		}
+	}
+	return err
+}
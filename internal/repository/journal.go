@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+// eventColumns lists the columns findEvents scans, in order.
+const eventColumns = `e.id, e.todo_id, e.action, e.changes, e.actor, e.occurred_at, e.operation, e.undone_at`
+
+// journalQuery selects the events of up to limit operations of actor. Without
+// undone these are the last operations still in effect, newest first; with it
+// the operations undone since the actor's last other change, the last one
+// undone first, which is the order redo replays them in.
+func journalQuery(b *queryBuilder, actor string, undone bool, limit int) string {
+	// Placeholders are bound in the order they are taken, which for SQLite
+	// has to be the order they appear in
+	cond, position, order := "e.actor = "+b.arg(actor), "MAX(e.id)", "DESC"
+	if undone {
+		cond += ` AND e.undone_at IS NOT NULL AND e.id > (
+					SELECT COALESCE(MAX(id), 0) FROM todo_events
+					WHERE actor = ` + b.arg(actor) + ` AND undone_at IS NULL
+				)`
+		position, order = "MIN(e.id)", "ASC"
+	} else {
+		cond += " AND e.undone_at IS NULL"
+	}
+
+	return `
+			WITH operations AS (
+				SELECT e.operation, ` + position + ` AS position
+				FROM todo_events e
+				WHERE ` + cond + `
+				GROUP BY e.operation
+				ORDER BY position ` + order + `
+				LIMIT ` + b.arg(limit) + `
+			)
+			SELECT ` + eventColumns + `
+			FROM todo_events e
+			JOIN operations o ON o.operation = e.operation
+			ORDER BY o.position ` + order + `, e.id`
+}
+
+// groupOperations splits events, sorted by operation as journalQuery returns
+// them, into their operations.
+func groupOperations(events []*domain.TodoEvent) []*domain.Operation {
+	var operations []*domain.Operation
+	for _, event := range events {
+		if n := len(operations); n == 0 || operations[n-1].ID != event.Operation {
+			operations = append(operations, &domain.Operation{ID: event.Operation})
+		}
+		last := operations[len(operations)-1]
+		last.Events = append(last.Events, event)
+	}
+	return operations
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+type ProjectRepository struct {
+	db pgxHandle
+}
+
+func NewProjectRepository(db *pgxpool.Pool) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+const selectProjects = `
+			SELECT
+				id,
+				name,
+				COALESCE(description, ''),
+				archived,
+				created_at,
+				updated_at
+			FROM projects`
+
+func (r *ProjectRepository) FindAll(ctx context.Context, includeArchived bool) ([]*domain.Project, error) {
+	query := selectProjects + `
+			WHERE archived = FALSE OR $1
+			ORDER BY name
+	`
+	rows, err := r.db.Query(ctx, query, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*domain.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (r *ProjectRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	query := selectProjects + `
+			WHERE id = $1
+	`
+	project, err := scanProject(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, pgError(err, "project "+id.String())
+	}
+	return project, nil
+}
+
+func (r *ProjectRepository) FindByName(ctx context.Context, name string) (*domain.Project, error) {
+	query := selectProjects + `
+			WHERE name = $1
+	`
+	project, err := scanProject(r.db.QueryRow(ctx, query, name))
+	if err != nil {
+		return nil, pgError(err, fmt.Sprintf("project %q", name))
+	}
+	return project, nil
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	query := `
+			INSERT INTO projects (id, name, description, archived, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		project.ID, project.Name, project.Description, project.Archived, project.CreatedAt, project.UpdatedAt)
+	return pgError(err, fmt.Sprintf("project %q", project.Name))
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	query := `
+			UPDATE projects
+			SET name = $1, description = $2, archived = $3, updated_at = $4
+			WHERE id = $5
+	`
+	tag, err := r.db.Exec(ctx, query,
+		project.Name, project.Description, project.Archived, project.UpdatedAt, project.ID)
+	if err != nil {
+		return pgError(err, fmt.Sprintf("project %q", project.Name))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("project %s: %w", project.ID, domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *ProjectRepository) Atomically(ctx context.Context, fn func(repo domain.ProjectRepository) error) error {
+	return pgTx(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&ProjectRepository{db: tx})
+	})
+}
+
+func scanProject(row pgx.Row) (*domain.Project, error) {
+	var project domain.Project
+
+	err := row.Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.Archived,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
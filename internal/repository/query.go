@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+type dialect int
+
+const (
+	postgres dialect = iota
+	sqlite
+)
+
+// queryBuilder collects WHERE conditions and their arguments, numbering
+// placeholders the way the dialect expects.
+type queryBuilder struct {
+	dialect    dialect
+	conditions []string
+	args       []any
+}
+
+// arg binds v and returns its placeholder.
+func (b *queryBuilder) arg(v any) string {
+	if b.dialect == sqlite {
+		switch value := v.(type) {
+		case time.Time:
+			v = sqliteTime(value)
+		case uuid.UUID:
+			v = value.String()
+		}
+	}
+
+	b.args = append(b.args, v)
+	if b.dialect == postgres {
+		return "$" + strconv.Itoa(len(b.args))
+	}
+	return "?"
+}
+
+// where adds a condition; each ? in cond is bound to the next of args.
+func (b *queryBuilder) where(cond string, args ...any) {
+	var sb strings.Builder
+	for _, arg := range args {
+		before, after, _ := strings.Cut(cond, "?")
+		sb.WriteString(before)
+		sb.WriteString(b.arg(arg))
+		cond = after
+	}
+	sb.WriteString(cond)
+
+	b.conditions = append(b.conditions, sb.String())
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// filterTodos translates the filters in q into conditions on the todos table.
+func filterTodos(b *queryBuilder, q domain.TodoQuery) {
+	switch {
+	case q.Trashed:
+		b.where("deleted_at IS NOT NULL")
+	case q.Archived:
+		b.where("deleted_at IS NULL AND archived_at IS NOT NULL")
+	case q.IncludeArchived:
+		b.where("deleted_at IS NULL")
+	default:
+		b.where("deleted_at IS NULL AND archived_at IS NULL")
+	}
+	if q.Completed != nil {
+		b.where("completed = ?", *q.Completed)
+	}
+	if q.Text != "" {
+		like := "LIKE"
+		if b.dialect == postgres {
+			like = "ILIKE"
+		}
+		pattern := "%" + likeEscaper.Replace(q.Text) + "%"
+		b.where(`(title `+like+` ? ESCAPE '\' OR COALESCE(description, '') `+like+` ? ESCAPE '\')`, pattern, pattern)
+	}
+	if q.DueBefore != nil {
+		b.where("due_date < ?", *q.DueBefore)
+	}
+	if q.DueAfter != nil {
+		b.where("due_date > ?", *q.DueAfter)
+	}
+	if q.CreatedBefore != nil {
+		b.where("created_at < ?", *q.CreatedBefore)
+	}
+	if q.CreatedAfter != nil {
+		b.where("created_at > ?", *q.CreatedAfter)
+	}
+	if q.CompletedBefore != nil {
+		b.where("completed_at < ?", *q.CompletedBefore)
+	}
+	if q.CompletedAfter != nil {
+		b.where("completed_at > ?", *q.CompletedAfter)
+	}
+	if q.ProjectID != nil {
+		b.where("project_id = ?", *q.ProjectID)
+	}
+	if q.ParentID != nil {
+		b.where("parent_id = ?", *q.ParentID)
+	}
+	if q.Blocked != nil {
+		exists := "EXISTS"
+		if !*q.Blocked {
+			exists = "NOT EXISTS"
+		}
+		b.where(exists + ` (
+				SELECT 1 FROM todo_dependencies d JOIN todos blocker ON blocker.id = d.blocker_id
+				WHERE d.todo_id = todos.id AND NOT blocker.completed AND blocker.deleted_at IS NULL
+			)`)
+	}
+	if q.Blocking != nil {
+		b.where("id IN (SELECT blocker_id FROM todo_dependencies WHERE todo_id = ?)", *q.Blocking)
+	}
+	if q.IDPrefix != "" {
+		b.where("CAST(id AS TEXT) LIKE ?", q.IDPrefix+"%")
+	}
+	if len(q.IDs) > 0 {
+		b.where("id IN (" + argList(b, q.IDs) + ")")
+	}
+	for _, tag := range q.Tags {
+		b.where(`id IN (
+				SELECT tt.todo_id FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.name = ?
+			)`, tag)
+	}
+}
+
+// versionClause matches each of todos at the version it has there, for
+// batch writes that must not overwrite a change made since the todos were
+// read.
+func versionClause(b *queryBuilder, todos []*domain.Todo) string {
+	matches := make([]string, len(todos))
+	for i, todo := range todos {
+		matches[i] = "(id = " + b.arg(todo.ID) + " AND version = " + b.arg(todo.Version) + ")"
+	}
+	return "(" + strings.Join(matches, " OR ") + ")"
+}
+
+// argList binds each of values, returning their placeholders for an IN
+// list.
+func argList[T any](b *queryBuilder, values []T) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = b.arg(v)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// deleteSubtreesQuery moves the not yet deleted descendants of ids to the
+// trash, stamped with deletedAt like Delete stamps a subtree.
+func deleteSubtreesQuery(b *queryBuilder, ids []uuid.UUID, deletedAt time.Time) string {
+	return `
+			UPDATE todos
+			SET deleted_at = ` + b.arg(deletedAt) + `, version = version + 1
+			WHERE deleted_at IS NULL AND id IN (
+				WITH RECURSIVE subtree(id) AS (
+					SELECT id FROM todos WHERE id IN (` + argList(b, ids) + `)
+					UNION
+					SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			)`
+}
+
+func todoIDs(todos []*domain.Todo) []uuid.UUID {
+	ids := make([]uuid.UUID, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
+
+// setClause builds the SET clause of an UPDATE that writes only the columns
+// patch supplies.
+func setClause(b *queryBuilder, patch domain.TodoPatch) string {
+	var assignments []string
+	set := func(column string, value any) {
+		assignments = append(assignments, column+" = "+b.arg(value))
+	}
+
+	if patch.Title != nil {
+		set("title", *patch.Title)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	if patch.Priority != nil {
+		set("priority", *patch.Priority)
+	}
+	if patch.ClearDueDate {
+		set("due_date", nil)
+	} else if patch.DueDate != nil {
+		set("due_date", *patch.DueDate)
+	}
+	if patch.ClearProject {
+		set("project_id", nil)
+	} else if patch.ProjectID != nil {
+		set("project_id", *patch.ProjectID)
+	}
+	if patch.ClearParent {
+		set("parent_id", nil)
+	} else if patch.ParentID != nil {
+		set("parent_id", *patch.ParentID)
+	}
+	if patch.Recurrence != nil {
+		set("recurrence", *patch.Recurrence)
+	}
+	set("updated_at", patch.UpdatedAt)
+	assignments = append(assignments, "version = version + 1")
+
+	return "SET " + strings.Join(assignments, ", ")
+}
+
+// completionClause continues a SET clause, marking the todo done at at or
+// pending again.
+func completionClause(b *queryBuilder, completed bool, at time.Time) string {
+	var completedAt any
+	if completed {
+		completedAt = at
+	}
+	return ", completed = " + b.arg(completed) + ", completed_at = " + b.arg(completedAt)
+}
+
+// likeEscaper escapes the LIKE wildcards in user input, for use with
+// ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// limitClause pages the query. It binds its values last, so it has to follow
+// every other clause that takes arguments.
+func limitClause(b *queryBuilder, q domain.TodoQuery) string {
+	if q.Limit <= 0 {
+		return ""
+	}
+
+	clause := "LIMIT " + b.arg(q.Limit)
+	if q.Offset > 0 {
+		clause += " OFFSET " + b.arg(q.Offset)
+	}
+	return clause
+}
+
+// sortColumns maps each sort field onto its column and natural direction.
+// Both PostgreSQL and SQLite understand the resulting ORDER BY clause.
+var sortColumns = map[domain.SortField]struct {
+	column string
+	desc   bool
+}{
+	domain.SortPriority: {column: "priority", desc: true},
+	domain.SortDue:      {column: "due_date", desc: false},
+	domain.SortCreated:  {column: "created_at", desc: true},
+}
+
+// orderByClause builds the ORDER BY clause for keys. Unknown fields are
+// skipped, todos without a due date always sort last, and the newest todo
+// wins any remaining tie.
+func orderByClause(keys []domain.SortKey) string {
+	var terms []string
+	for _, key := range keys {
+		sort, ok := sortColumns[key.Field]
+		if !ok {
+			continue
+		}
+
+		direction := "ASC"
+		if sort.desc != key.Reverse {
+			direction = "DESC"
+		}
+		terms = append(terms, sort.column+" "+direction+" NULLS LAST")
+	}
+	terms = append(terms, "created_at DESC", "id")
+
+	return "ORDER BY " + strings.Join(terms, ", ")
+}
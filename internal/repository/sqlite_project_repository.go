@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+type SQLiteProjectRepository struct {
+	db sqliteHandle
+}
+
+func NewSQLiteProjectRepository(db *sql.DB) *SQLiteProjectRepository {
+	return &SQLiteProjectRepository{db: db}
+}
+
+func (r *SQLiteProjectRepository) FindAll(ctx context.Context, includeArchived bool) ([]*domain.Project, error) {
+	query := selectProjects + `
+			WHERE archived = FALSE OR ?
+			ORDER BY name
+	`
+	rows, err := r.db.QueryContext(ctx, query, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*domain.Project
+	for rows.Next() {
+		project, err := scanSQLiteProject(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (r *SQLiteProjectRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	query := selectProjects + `
+			WHERE id = ?
+	`
+	project, err := scanSQLiteProject(r.db.QueryRowContext(ctx, query, id.String()))
+	if err != nil {
+		return nil, sqliteError(err, "project "+id.String())
+	}
+	return project, nil
+}
+
+func (r *SQLiteProjectRepository) FindByName(ctx context.Context, name string) (*domain.Project, error) {
+	query := selectProjects + `
+			WHERE name = ?
+	`
+	project, err := scanSQLiteProject(r.db.QueryRowContext(ctx, query, name))
+	if err != nil {
+		return nil, sqliteError(err, fmt.Sprintf("project %q", name))
+	}
+	return project, nil
+}
+
+func (r *SQLiteProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	query := `
+			INSERT INTO projects (id, name, description, archived, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		project.ID.String(), project.Name, project.Description, project.Archived,
+		sqliteTime(project.CreatedAt), sqliteTime(project.UpdatedAt))
+	return sqliteError(err, fmt.Sprintf("project %q", project.Name))
+}
+
+func (r *SQLiteProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	query := `
+			UPDATE projects
+			SET name = ?, description = ?, archived = ?, updated_at = ?
+			WHERE id = ?
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		project.Name, project.Description, project.Archived, sqliteTime(project.UpdatedAt), project.ID.String())
+	if err != nil {
+		return sqliteError(err, fmt.Sprintf("project %q", project.Name))
+	}
+	return checkSQLiteRowsAffected(result, "project "+project.ID.String())
+}
+
+func (r *SQLiteProjectRepository) Atomically(ctx context.Context, fn func(repo domain.ProjectRepository) error) error {
+	return sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&SQLiteProjectRepository{db: tx})
+	})
+}
+
+func scanSQLiteProject(row sqliteScanner) (*domain.Project, error) {
+	var (
+		project              domain.Project
+		createdAt, updatedAt string
+	)
+
+	err := row.Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.Archived,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if project.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
@@ -0,0 +1,700 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	_ "modernc.org/sqlite"
+)
+
+type SQLiteTodoRepository struct {
+	db sqliteHandle
+}
+
+// sqliteHandle is what the repositories need from a database, or from the
+// transaction Atomically runs in.
+type sqliteHandle interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func NewSQLiteTodoRepository(db *sql.DB) *SQLiteTodoRepository {
+	return &SQLiteTodoRepository{db: db}
+}
+
+// OpenSQLite opens the database file at path. Use ":memory:" for a throwaway
+// database.
+func OpenSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	// Pragmas in the DSN apply to every connection the pool opens.
+	// Transactions take the write lock when they begin: a transaction that
+	// reads first and then upgrades its lock fails with SQLITE_BUSY right
+	// away instead of waiting out busy_timeout.
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sqlite database: %v", err)
+	}
+
+	// SQLite allows a single writer; sharing one connection also keeps
+	// in-memory databases alive for the lifetime of the pool.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to open sqlite database: %v", err)
+	}
+
+	return db, nil
+}
+
+// selectSQLiteTodos packs the tag names into one comma-separated column;
+// tags never contain commas.
+const selectSQLiteTodos = `
+			SELECT` + todoColumns + `,
+				(
+					SELECT group_concat(name, ',') FROM (
+						SELECT t.name FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+						WHERE tt.todo_id = todos.id
+						ORDER BY t.name
+					)
+				) AS tags
+			FROM todos`
+
+func (r *SQLiteTodoRepository) FindAll(ctx context.Context, q domain.TodoQuery) ([]*domain.Todo, error) {
+	b := &queryBuilder{dialect: sqlite}
+	filterTodos(b, q)
+
+	query := selectSQLiteTodos + `
+			` + b.whereClause() + `
+			` + orderByClause(q.Sort) + `
+			` + limitClause(b, q)
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var todos []*domain.Todo
+	for rows.Next() {
+		todo, err := scanSQLiteTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		todos = append(todos, todo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+func (r *SQLiteTodoRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	query := selectSQLiteTodos + `
+			WHERE id = ? AND deleted_at IS NULL
+	`
+	todo, err := scanSQLiteTodo(r.db.QueryRowContext(ctx, query, id.String()))
+	if err != nil {
+		return nil, sqliteError(err, "todo "+id.String())
+	}
+	return todo, nil
+}
+
+func (r *SQLiteTodoRepository) ResolveID(ctx context.Context, ref string, scope domain.TodoQuery) (uuid.UUID, error) {
+	return resolveID(ctx, r, ref, scope)
+}
+
+func (r *SQLiteTodoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	query := `
+			INSERT INTO todos (id, title, description, completed, completed_at, priority, due_date, project_id, parent_id, recurrence, created_at, updated_at, version)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	err := sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query,
+			todo.ID.String(), todo.Title, todo.Description, todo.Completed, sqliteNullTime(todo.CompletedAt), todo.Priority, sqliteNullTime(todo.DueDate),
+			todo.ProjectID, todo.ParentID, todo.Recurrence, sqliteTime(todo.CreatedAt), sqliteTime(todo.UpdatedAt), todo.Version)
+		if err != nil {
+			return err
+		}
+
+		return addSQLiteTags(ctx, tx, todo.ID, todo.Tags)
+	})
+	return sqliteError(err, "todo "+todo.ID.String())
+}
+
+func (r *SQLiteTodoRepository) Update(ctx context.Context, id uuid.UUID, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: sqlite}
+	return r.update(ctx, b, setClause(b, patch), id, patch.Version)
+}
+
+func (r *SQLiteTodoRepository) SetCompleted(ctx context.Context, id uuid.UUID, completed bool, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: sqlite}
+	return r.update(ctx, b, setClause(b, patch)+completionClause(b, completed, patch.UpdatedAt), id, patch.Version)
+}
+
+// update runs an UPDATE of todo id with the SET clause set, whose arguments
+// are already bound to b, as long as the todo is still at version.
+func (r *SQLiteTodoRepository) update(ctx context.Context, b *queryBuilder, set string, id uuid.UUID, version int) error {
+	query := `
+			UPDATE todos
+			` + set + `
+			WHERE id = ` + b.arg(id) + ` AND version = ` + b.arg(version) + ` AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, b.args...)
+	if err != nil {
+		return sqliteError(err, "todo "+id.String())
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+
+	// Nothing matched: either the todo is gone or someone else got there first
+	var current int
+	err = r.db.QueryRowContext(ctx, `SELECT version FROM todos WHERE id = ? AND deleted_at IS NULL`, id.String()).Scan(&current)
+	if err != nil {
+		return sqliteError(err, "todo "+id.String())
+	}
+	return staleTodoError(id, current)
+}
+
+func (r *SQLiteTodoRepository) UpdateMany(ctx context.Context, todos []*domain.Todo, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: sqlite}
+	return r.updateMany(ctx, b, setClause(b, patch), todos)
+}
+
+func (r *SQLiteTodoRepository) SetCompletedMany(ctx context.Context, todos []*domain.Todo, completed bool, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: sqlite}
+	return r.updateMany(ctx, b, setClause(b, patch)+completionClause(b, completed, patch.UpdatedAt), todos)
+}
+
+// updateMany is update for every one of todos, at the version each has
+// there.
+func (r *SQLiteTodoRepository) updateMany(ctx context.Context, b *queryBuilder, set string, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	query := `
+			UPDATE todos
+			` + set + `
+			WHERE ` + versionClause(b, todos) + ` AND deleted_at IS NULL`
+
+	return sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, b.args...)
+		if err != nil {
+			return sqliteError(err, "todos")
+		}
+		return checkSQLiteBatch(result, len(todos))
+	})
+}
+
+func (r *SQLiteTodoRepository) Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	query := `
+			UPDATE todos
+			SET deleted_at = ?, version = version + 1
+			WHERE deleted_at IS NULL AND id IN (
+				WITH RECURSIVE subtree(id) AS (
+					SELECT ?
+					UNION
+					SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			)
+	`
+	result, err := r.db.ExecContext(ctx, query, sqliteTime(deletedAt), id.String())
+	if err != nil {
+		return sqliteError(err, "todo "+id.String())
+	}
+	return checkSQLiteRowsAffected(result, "todo "+id.String())
+}
+
+func (r *SQLiteTodoRepository) DeleteMany(ctx context.Context, todos []*domain.Todo, deletedAt time.Time) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	b := &queryBuilder{dialect: sqlite}
+	query := `
+			UPDATE todos
+			SET deleted_at = ` + b.arg(deletedAt) + `, version = version + 1
+			WHERE ` + versionClause(b, todos) + ` AND deleted_at IS NULL`
+
+	err := sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, b.args...)
+		if err != nil {
+			return err
+		}
+		if err := checkSQLiteBatch(result, len(todos)); err != nil {
+			return err
+		}
+
+		subtrees := &queryBuilder{dialect: sqlite}
+		_, err = tx.ExecContext(ctx, deleteSubtreesQuery(subtrees, todoIDs(todos), deletedAt), subtrees.args...)
+		return err
+	})
+	return sqliteError(err, "todos")
+}
+
+// Restore matches the subtasks deleted along with the todo by their
+// deleted_at, which Delete stamps on the whole subtree at once.
+func (r *SQLiteTodoRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `
+			UPDATE todos
+			SET deleted_at = NULL, version = version + 1
+			WHERE deleted_at = (SELECT deleted_at FROM todos WHERE id = ?1) AND id IN (
+				WITH RECURSIVE subtree(id) AS (
+					SELECT ?1
+					UNION
+					SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			)
+	`
+	result, err := r.db.ExecContext(ctx, query, id.String())
+	if err != nil {
+		return sqliteError(err, "todo "+id.String())
+	}
+	return checkSQLiteRowsAffected(result, "todo "+id.String()+" in the trash")
+}
+
+func (r *SQLiteTodoRepository) Archive(ctx context.Context, q domain.TodoQuery, archivedAt time.Time) (int, error) {
+	b := &queryBuilder{dialect: sqlite}
+	set := "SET archived_at = " + b.arg(archivedAt) + ", version = version + 1"
+
+	// Only todos that are not archived yet
+	q.Archived, q.IncludeArchived, q.Trashed = false, false, false
+	filterTodos(b, q)
+
+	result, err := r.db.ExecContext(ctx, "UPDATE todos "+set+" "+b.whereClause(), b.args...)
+	if err != nil {
+		return 0, sqliteError(err, "todos")
+	}
+	archived, err := result.RowsAffected()
+	return int(archived), err
+}
+
+func (r *SQLiteTodoRepository) Unarchive(ctx context.Context, id uuid.UUID) error {
+	query := `
+			UPDATE todos
+			SET archived_at = NULL, version = version + 1
+			WHERE id = ? AND archived_at IS NOT NULL AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, id.String())
+	if err != nil {
+		return sqliteError(err, "todo "+id.String())
+	}
+	return checkSQLiteRowsAffected(result, "archived todo "+id.String())
+}
+
+// Purge removes the todos for good. Subtasks purged alongside their parent
+// carry the same deleted_at, so no walk is needed here.
+func (r *SQLiteTodoRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	query := `
+			DELETE FROM todos
+			WHERE deleted_at < ?
+	`
+	result, err := r.db.ExecContext(ctx, query, sqliteTime(before))
+	if err != nil {
+		return 0, sqliteError(err, "todos")
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+func (r *SQLiteTodoRepository) AddTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	err := sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return addSQLiteTags(ctx, tx, id, tags)
+	})
+	return sqliteError(err, "todo "+id.String())
+}
+
+func addSQLiteTags(ctx context.Context, tx *sql.Tx, id uuid.UUID, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tags (name)
+			VALUES (?)
+			ON CONFLICT (name) DO NOTHING
+		`, tag)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO todo_tags (todo_id, tag_id)
+			SELECT ?, id FROM tags WHERE name = ?
+			ON CONFLICT DO NOTHING
+		`, id.String(), tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLiteTodoRepository) RemoveTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	err := sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, tag := range tags {
+			_, err := tx.ExecContext(ctx, `
+				DELETE FROM todo_tags
+				WHERE todo_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ?)
+			`, id.String(), tag)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return sqliteError(err, "todo "+id.String())
+}
+
+func (r *SQLiteTodoRepository) AddTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error {
+	err := sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, tag := range tags {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO tags (name)
+				VALUES (?)
+				ON CONFLICT (name) DO NOTHING
+			`, tag)
+			if err != nil {
+				return err
+			}
+		}
+
+		b := &queryBuilder{dialect: sqlite}
+		query := `
+				INSERT INTO todo_tags (todo_id, tag_id)
+				SELECT todo.id, tag.id
+				FROM todos todo
+				CROSS JOIN tags tag
+				WHERE todo.id IN (` + argList(b, ids) + `) AND tag.name IN (` + argList(b, tags) + `)
+				ON CONFLICT DO NOTHING
+		`
+		_, err := tx.ExecContext(ctx, query, b.args...)
+		return err
+	})
+	return sqliteError(err, "todos")
+}
+
+func (r *SQLiteTodoRepository) RemoveTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error {
+	b := &queryBuilder{dialect: sqlite}
+	query := `
+			DELETE FROM todo_tags
+			WHERE todo_id IN (` + argList(b, ids) + `)
+			AND tag_id IN (SELECT id FROM tags WHERE name IN (` + argList(b, tags) + `))
+	`
+	_, err := r.db.ExecContext(ctx, query, b.args...)
+	return sqliteError(err, "todos")
+}
+
+func (r *SQLiteTodoRepository) FindAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	query := `
+			SELECT t.name, COUNT(*)
+			FROM tags t
+			JOIN todo_tags tt ON tt.tag_id = t.id
+			JOIN todos ON todos.id = tt.todo_id AND todos.deleted_at IS NULL AND todos.archived_at IS NULL
+			GROUP BY t.name
+			ORDER BY COUNT(*) DESC, t.name
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*domain.TagCount
+	for rows.Next() {
+		var tag domain.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *SQLiteTodoRepository) AddBlocker(ctx context.Context, id, blockerID uuid.UUID) error {
+	query := `
+			INSERT INTO todo_dependencies (todo_id, blocker_id)
+			VALUES (?, ?)
+			ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, id.String(), blockerID.String())
+	return sqliteError(err, "todo "+id.String())
+}
+
+func (r *SQLiteTodoRepository) RemoveBlocker(ctx context.Context, id, blockerID uuid.UUID) error {
+	query := `
+			DELETE FROM todo_dependencies
+			WHERE todo_id = ? AND blocker_id = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, id.String(), blockerID.String())
+	return sqliteError(err, "todo "+id.String())
+}
+
+func (r *SQLiteTodoRepository) AddEvent(ctx context.Context, event *domain.TodoEvent) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+			INSERT INTO todo_events (todo_id, action, changes, actor, occurred_at, operation)
+			VALUES (?, ?, ?, ?, ?, ?)
+			RETURNING id
+	`
+	err = r.db.QueryRowContext(ctx, query,
+		event.TodoID.String(),
+		event.Action,
+		string(changes),
+		event.Actor,
+		sqliteTime(event.OccurredAt),
+		event.Operation.String(),
+	).Scan(&event.ID)
+	return sqliteError(err, "todo "+event.TodoID.String())
+}
+
+func (r *SQLiteTodoRepository) FindEvents(ctx context.Context, id uuid.UUID) ([]*domain.TodoEvent, error) {
+	query := `
+			SELECT ` + eventColumns + `
+			FROM todo_events e
+			WHERE e.todo_id = ?
+			ORDER BY e.id
+	`
+	return r.findEvents(ctx, query, id.String())
+}
+
+func (r *SQLiteTodoRepository) FindUndoable(ctx context.Context, actor string, limit int) ([]*domain.Operation, error) {
+	b := &queryBuilder{dialect: sqlite}
+	events, err := r.findEvents(ctx, journalQuery(b, actor, false, limit), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	return groupOperations(events), nil
+}
+
+func (r *SQLiteTodoRepository) FindRedoable(ctx context.Context, actor string, limit int) ([]*domain.Operation, error) {
+	b := &queryBuilder{dialect: sqlite}
+	events, err := r.findEvents(ctx, journalQuery(b, actor, true, limit), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	return groupOperations(events), nil
+}
+
+func (r *SQLiteTodoRepository) findEvents(ctx context.Context, query string, args ...any) ([]*domain.TodoEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.TodoEvent
+	for rows.Next() {
+		var (
+			event               domain.TodoEvent
+			changes, occurredAt string
+			undoneAt            sql.NullString
+		)
+		err := rows.Scan(
+			&event.ID,
+			&event.TodoID,
+			&event.Action,
+			&changes,
+			&event.Actor,
+			&occurredAt,
+			&event.Operation,
+			&undoneAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &event.Changes); err != nil {
+			return nil, err
+		}
+		if event.OccurredAt, err = parseSQLiteTime(occurredAt); err != nil {
+			return nil, err
+		}
+		if event.UndoneAt, err = parseSQLiteNullTime(undoneAt); err != nil {
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *SQLiteTodoRepository) SetUndone(ctx context.Context, operation uuid.UUID, undoneAt *time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE todo_events SET undone_at = ? WHERE operation = ?`,
+		sqliteNullTime(undoneAt), operation.String())
+	if err != nil {
+		return sqliteError(err, "operation "+operation.String())
+	}
+	return checkSQLiteRowsAffected(result, "operation "+operation.String())
+}
+
+func (r *SQLiteTodoRepository) Atomically(ctx context.Context, fn func(repo domain.TodoRepository) error) error {
+	return sqliteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&SQLiteTodoRepository{db: tx})
+	})
+}
+
+// sqliteTx runs fn in a transaction, committing only if it succeeds. Inside
+// Atomically, fn joins the transaction that is already open.
+func sqliteTx(ctx context.Context, db sqliteHandle, fn func(tx *sql.Tx) error) error {
+	if tx, ok := db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := db.(*sql.DB).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkSQLiteBatch reports a batch write that matched fewer than total
+// todos as stale.
+func checkSQLiteBatch(result sql.Result, total int) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < int64(total) {
+		return staleBatchError(n, total)
+	}
+	return nil
+}
+
+// checkSQLiteRowsAffected reports subject as not found when the statement
+// matched no rows.
+func checkSQLiteRowsAffected(result sql.Result, subject string) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", subject, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// sqliteTimeLayout sorts lexically in chronological order, which lets ORDER BY
+// and range comparisons work on the stored text.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000000"
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func sqliteNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: sqliteTime(*t), Valid: true}
+}
+
+func parseSQLiteTime(s string) (time.Time, error) {
+	// CURRENT_TIMESTAMP defaults have no fractional seconds.
+	for _, layout := range []string{sqliteTimeLayout, "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t.Local(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid sqlite timestamp %q", s)
+}
+
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+func parseSQLiteNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseSQLiteTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanSQLiteTodo(row sqliteScanner) (*domain.Todo, error) {
+	var (
+		todo                       domain.Todo
+		description, dueDate, tags sql.NullString
+		completedAt, deletedAt     sql.NullString
+		archivedAt                 sql.NullString
+		createdAt, updatedAt       string
+	)
+
+	err := row.Scan(
+		&todo.ID,
+		&todo.Title,
+		&description,
+		&todo.Completed,
+		&todo.Priority,
+		&dueDate,
+		&todo.ProjectID,
+		&todo.ParentID,
+		&todo.Subtasks.Done,
+		&todo.Subtasks.Total,
+		&todo.OpenBlockers,
+		&todo.Recurrence,
+		&createdAt,
+		&updatedAt,
+		&todo.Version,
+		&completedAt,
+		&deletedAt,
+		&archivedAt,
+		&tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	todo.Description = description.String
+	todo.Tags = []string{}
+	if tags.Valid {
+		todo.Tags = strings.Split(tags.String, ",")
+	}
+	if todo.DueDate, err = parseSQLiteNullTime(dueDate); err != nil {
+		return nil, err
+	}
+	if todo.CompletedAt, err = parseSQLiteNullTime(completedAt); err != nil {
+		return nil, err
+	}
+	if todo.DeletedAt, err = parseSQLiteNullTime(deletedAt); err != nil {
+		return nil, err
+	}
+	if todo.ArchivedAt, err = parseSQLiteNullTime(archivedAt); err != nil {
+		return nil, err
+	}
+	if todo.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if todo.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
@@ -0,0 +1,787 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/migrate"
+	"github.com/leandrowiemesfilho/go-todo-cli/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLiteTodoRepositoryTestSuite struct {
+	suite.Suite
+	db       *sql.DB
+	repo     *SQLiteTodoRepository
+	ctx      context.Context
+	testTodo *domain.Todo
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+
+	// Every test gets a fresh in-memory database
+	db, err := OpenSQLite(suite.ctx, ":memory:")
+	suite.Require().NoError(err)
+
+	schema, err := migrate.Load(migrations.SQLite, "sqlite")
+	suite.Require().NoError(err)
+	_, err = migrate.New(migrate.NewSQLiteDriver(db), schema).Up(suite.ctx)
+	suite.Require().NoError(err)
+
+	suite.db = db
+	suite.repo = NewSQLiteTodoRepository(db)
+
+	suite.testTodo = &domain.Todo{
+		ID:          uuid.New(),
+		Title:       "Test TODO",
+		Description: "Test Description",
+		Completed:   false,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Version:     1,
+	}
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestFindAll() {
+	now := time.Now()
+	todos := []*domain.Todo{
+		{
+			ID:        uuid.New(),
+			Title:     "Todo 1",
+			CreatedAt: now.Add(-time.Hour),
+			UpdatedAt: now,
+		},
+		{
+			ID:        uuid.New(),
+			Title:     "Todo 2",
+			Completed: true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	for _, v := range todos {
+		err := suite.repo.Create(suite.ctx, v)
+		assert.NoError(suite.T(), err)
+	}
+
+	foundTodos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), foundTodos, len(todos))
+
+	// Newest first
+	assert.Equal(suite.T(), todos[1].ID, foundTodos[0].ID)
+	assert.True(suite.T(), foundTodos[0].Completed)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestFindAll_Sort() {
+	now := time.Now()
+	soon, later := now.Add(time.Hour), now.Add(48*time.Hour)
+	low := &domain.Todo{ID: uuid.New(), Title: "low", Priority: domain.PriorityLow, DueDate: &soon, CreatedAt: now, UpdatedAt: now}
+	high := &domain.Todo{ID: uuid.New(), Title: "high", Priority: domain.PriorityHigh, DueDate: &later, CreatedAt: now.Add(-time.Hour), UpdatedAt: now}
+	undated := &domain.Todo{ID: uuid.New(), Title: "undated", Priority: domain.PriorityHigh, CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now}
+
+	for _, v := range []*domain.Todo{low, high, undated} {
+		suite.Require().NoError(suite.repo.Create(suite.ctx, v))
+	}
+
+	titles := func(sort string) []string {
+		keys, err := domain.ParseSort(sort)
+		suite.Require().NoError(err)
+
+		todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Sort: keys})
+		suite.Require().NoError(err)
+
+		var result []string
+		for _, todo := range todos {
+			result = append(result, todo.Title)
+		}
+		return result
+	}
+
+	assert.Equal(suite.T(), []string{"high", "undated", "low"}, titles("priority"))
+	assert.Equal(suite.T(), []string{"low", "high", "undated"}, titles("due"))
+	assert.Equal(suite.T(), []string{"high", "low", "undated"}, titles("-due"))
+	assert.Equal(suite.T(), []string{"high", "undated", "low"}, titles("priority,due"))
+	assert.Equal(suite.T(), []string{"undated", "high", "low"}, titles("-created"))
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestFindAll_Filters() {
+	now := time.Now()
+	yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+	todos := []*domain.Todo{
+		{ID: uuid.New(), Title: "Rotate certs", DueDate: &yesterday, CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now},
+		{ID: uuid.New(), Title: "Write docs", Description: "100% of the CERT guide", Completed: true, CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now},
+		{ID: uuid.New(), Title: "Ship release", DueDate: &tomorrow, CreatedAt: now.Add(-time.Hour), UpdatedAt: now},
+	}
+	for _, todo := range todos {
+		err := suite.repo.Create(suite.ctx, todo)
+		assert.NoError(suite.T(), err)
+	}
+
+	titles := func(q domain.TodoQuery) []string {
+		found, err := suite.repo.FindAll(suite.ctx, q)
+		suite.Require().NoError(err)
+
+		var titles []string
+		for _, todo := range found {
+			titles = append(titles, todo.Title)
+		}
+		return titles
+	}
+
+	completed, pending := true, false
+	assert.Equal(suite.T(), []string{"Write docs"}, titles(domain.TodoQuery{Completed: &completed}))
+	assert.Equal(suite.T(), []string{"Ship release", "Rotate certs"}, titles(domain.TodoQuery{Completed: &pending}))
+
+	// Text matches title or description, ignoring case; wildcards are literal
+	assert.Equal(suite.T(), []string{"Write docs", "Rotate certs"}, titles(domain.TodoQuery{Text: "cert"}))
+	assert.Equal(suite.T(), []string{"Write docs"}, titles(domain.TodoQuery{Text: "100%"}))
+	assert.Empty(suite.T(), titles(domain.TodoQuery{Text: "_"}))
+
+	assert.Equal(suite.T(), []string{"Rotate certs"}, titles(domain.TodoQuery{Completed: &pending, DueBefore: &now}))
+	assert.Equal(suite.T(), []string{"Ship release"}, titles(domain.TodoQuery{DueAfter: &now}))
+
+	createdAfter := now.Add(-150 * time.Minute)
+	assert.Equal(suite.T(), []string{"Ship release", "Write docs"}, titles(domain.TodoQuery{CreatedAfter: &createdAfter}))
+
+	// Pages follow the sort order
+	assert.Equal(suite.T(), []string{"Ship release", "Write docs"}, titles(domain.TodoQuery{Limit: 2}))
+	assert.Equal(suite.T(), []string{"Rotate certs"}, titles(domain.TodoQuery{Limit: 2, Offset: 2}))
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestFindById() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.testTodo.ID, todo.ID)
+	assert.Equal(suite.T(), suite.testTodo.Title, todo.Title)
+	assert.Equal(suite.T(), suite.testTodo.Description, todo.Description)
+	assert.WithinDuration(suite.T(), suite.testTodo.CreatedAt, todo.CreatedAt, time.Microsecond)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestDueDate() {
+	dueDate := time.Now().Add(48 * time.Hour)
+	suite.testTodo.DueDate = &dueDate
+
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	suite.Require().NotNil(todo.DueDate)
+	assert.WithinDuration(suite.T(), dueDate, *todo.DueDate, time.Microsecond)
+
+	// Clearing the due date stores NULL
+	err = suite.repo.Update(suite.ctx, todo.ID, domain.TodoPatch{ClearDueDate: true, UpdatedAt: time.Now(), Version: todo.Version})
+	assert.NoError(suite.T(), err)
+
+	todo, err = suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), todo.DueDate)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestTags() {
+	suite.testTodo.Tags = []string{"infra", "bug"}
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	other := &domain.Todo{ID: uuid.New(), Title: "Other", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err = suite.repo.Create(suite.ctx, other)
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.AddTags(suite.ctx, other.ID, []string{"bug", "personal"})
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"bug", "infra"}, todo.Tags)
+
+	// Filtering requires every tag
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Tags: []string{"bug"}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 2)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{Tags: []string{"bug", "personal"}})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(todos, 1)
+	assert.Equal(suite.T(), other.ID, todos[0].ID)
+
+	err = suite.repo.RemoveTags(suite.ctx, other.ID, []string{"bug", "personal"})
+	assert.NoError(suite.T(), err)
+
+	todo, err = suite.repo.FindByID(suite.ctx, other.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), todo.Tags)
+
+	tags, err := suite.repo.FindAllTags(suite.ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []*domain.TagCount{{Name: "bug", Count: 1}, {Name: "infra", Count: 1}}, tags)
+
+	// Todos in the trash no longer count towards their tags
+	err = suite.repo.Delete(suite.ctx, suite.testTodo.ID, time.Now())
+	assert.NoError(suite.T(), err)
+
+	tags, err = suite.repo.FindAllTags(suite.ctx)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), tags)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestProjects() {
+	projects := NewSQLiteProjectRepository(suite.db)
+
+	project := &domain.Project{ID: uuid.New(), Name: "infra", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err := projects.Create(suite.ctx, project)
+	assert.NoError(suite.T(), err)
+
+	found, err := projects.FindByName(suite.ctx, "infra")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), project.ID, found.ID)
+
+	_, err = projects.FindByName(suite.ctx, "missing")
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+
+	// Project names are unique
+	duplicate := &domain.Project{ID: uuid.New(), Name: "infra", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err = projects.Create(suite.ctx, duplicate)
+	assert.ErrorIs(suite.T(), err, domain.ErrConflict)
+
+	suite.testTodo.ProjectID = &project.ID
+	err = suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	other := &domain.Todo{ID: uuid.New(), Title: "Other", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err = suite.repo.Create(suite.ctx, other)
+	assert.NoError(suite.T(), err)
+
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{ProjectID: &project.ID})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(todos, 1)
+	assert.Equal(suite.T(), suite.testTodo.ID, todos[0].ID)
+	assert.Equal(suite.T(), &project.ID, todos[0].ProjectID)
+
+	// Archived projects drop out of the default listing
+	project.Archived = true
+	err = projects.Update(suite.ctx, project)
+	assert.NoError(suite.T(), err)
+
+	active, err := projects.FindAll(suite.ctx, false)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), active)
+
+	all, err := projects.FindAll(suite.ctx, true)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), all, 1)
+
+	// A failed unit of work leaves the project as it was
+	failed := errors.New("failed")
+	err = projects.Atomically(suite.ctx, func(repo domain.ProjectRepository) error {
+		renamed, err := repo.FindByID(suite.ctx, project.ID)
+		if err != nil {
+			return err
+		}
+		renamed.Name = "platform"
+		if err := repo.Update(suite.ctx, renamed); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(suite.T(), err, failed)
+
+	found, err = projects.FindByID(suite.ctx, project.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "infra", found.Name)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestSubtasks() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	parentID := suite.testTodo.ID
+	child := &domain.Todo{ID: uuid.New(), Title: "Child", Completed: true, ParentID: &parentID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	other := &domain.Todo{ID: uuid.New(), Title: "Other child", ParentID: &parentID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	grandchild := &domain.Todo{ID: uuid.New(), Title: "Grandchild", ParentID: &child.ID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	for _, todo := range []*domain.Todo{child, other, grandchild} {
+		err = suite.repo.Create(suite.ctx, todo)
+		assert.NoError(suite.T(), err)
+	}
+
+	parent, err := suite.repo.FindByID(suite.ctx, parentID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), domain.Progress{Done: 1, Total: 2}, parent.Subtasks)
+
+	children, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{ParentID: &parentID})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), children, 2)
+
+	// Deleting a todo moves its whole subtree to the trash
+	err = suite.repo.Delete(suite.ctx, parentID, time.Now())
+	assert.NoError(suite.T(), err)
+
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), todos)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{Trashed: true})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 4)
+
+	// and restoring it brings the subtree back
+	err = suite.repo.Restore(suite.ctx, parentID)
+	assert.NoError(suite.T(), err)
+
+	parent, err = suite.repo.FindByID(suite.ctx, parentID)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), parent.DeletedAt)
+	assert.Equal(suite.T(), domain.Progress{Done: 1, Total: 2}, parent.Subtasks)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestBlockers() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	open := &domain.Todo{ID: uuid.New(), Title: "Open", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	done := &domain.Todo{ID: uuid.New(), Title: "Done", Completed: true, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	for _, todo := range []*domain.Todo{open, done} {
+		err = suite.repo.Create(suite.ctx, todo)
+		assert.NoError(suite.T(), err)
+
+		err = suite.repo.AddBlocker(suite.ctx, suite.testTodo.ID, todo.ID)
+		assert.NoError(suite.T(), err)
+	}
+
+	// Adding the same blocker twice is a no-op
+	err = suite.repo.AddBlocker(suite.ctx, suite.testTodo.ID, open.ID)
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, todo.OpenBlockers)
+
+	blockers, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Blocking: &suite.testTodo.ID})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), blockers, 2)
+
+	blocked := true
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Blocked: &blocked})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(todos, 1)
+	assert.Equal(suite.T(), suite.testTodo.ID, todos[0].ID)
+
+	err = suite.repo.RemoveBlocker(suite.ctx, suite.testTodo.ID, open.ID)
+	assert.NoError(suite.T(), err)
+
+	blocked = false
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{Blocked: &blocked})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 3)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestResolveID() {
+	first := &domain.Todo{ID: uuid.MustParse("abcd1234-0000-4000-8000-000000000001"), Title: "First", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	second := &domain.Todo{ID: uuid.MustParse("abcd5678-0000-4000-8000-000000000002"), Title: "Second", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	for _, todo := range []*domain.Todo{first, second} {
+		err := suite.repo.Create(suite.ctx, todo)
+		assert.NoError(suite.T(), err)
+	}
+
+	id, err := suite.repo.ResolveID(suite.ctx, "ABCD12", domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first.ID, id)
+
+	id, err = suite.repo.ResolveID(suite.ctx, second.ID.String(), domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), second.ID, id)
+
+	_, err = suite.repo.ResolveID(suite.ctx, "abcd", domain.TodoQuery{})
+	var ambiguous *domain.AmbiguousIDError
+	suite.Require().ErrorAs(err, &ambiguous)
+	assert.Len(suite.T(), ambiguous.Candidates, 2)
+	assert.ErrorIs(suite.T(), err, domain.ErrValidation)
+
+	_, err = suite.repo.ResolveID(suite.ctx, "ffff", domain.TodoQuery{})
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+
+	// LIKE wildcards are not IDs
+	_, err = suite.repo.ResolveID(suite.ctx, "%", domain.TodoQuery{})
+	assert.ErrorIs(suite.T(), err, domain.ErrValidation)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestFindById_NotFound() {
+	_, err := suite.repo.FindByID(suite.ctx, uuid.New())
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestCreateTodo_DuplicatedID() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.ErrorIs(suite.T(), err, domain.ErrConflict)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestUpdateTodo() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	description := "Updated description"
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{Description: &description, UpdatedAt: time.Now(), Version: 1})
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), description, todo.Description)
+
+	// Columns the patch leaves out keep their values
+	assert.Equal(suite.T(), suite.testTodo.Title, todo.Title)
+	assert.Equal(suite.T(), suite.testTodo.Priority, todo.Priority)
+	assert.True(suite.T(), todo.UpdatedAt.After(suite.testTodo.UpdatedAt))
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestUpdateTodo_NotFound() {
+	err := suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{UpdatedAt: time.Now(), Version: 1})
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestUpdateTodo_Stale() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	first, second := "First writer", "Second writer"
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{Title: &first, UpdatedAt: time.Now(), Version: 1})
+	assert.NoError(suite.T(), err)
+
+	// A patch based on the version the first writer replaced is rejected
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{Title: &second, UpdatedAt: time.Now(), Version: 1})
+	assert.ErrorIs(suite.T(), err, domain.ErrStale)
+	assert.ErrorIs(suite.T(), err, domain.ErrConflict)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first, todo.Title)
+	assert.Equal(suite.T(), 2, todo.Version)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestSetCompleted() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	completedAt := time.Now()
+	err = suite.repo.SetCompleted(suite.ctx, suite.testTodo.ID, true, domain.TodoPatch{UpdatedAt: completedAt, Version: 1})
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), todo.Completed)
+	suite.Require().NotNil(todo.CompletedAt)
+	assert.WithinDuration(suite.T(), completedAt, *todo.CompletedAt, time.Microsecond)
+	assert.Equal(suite.T(), 2, todo.Version)
+
+	// Reopening clears the completion time
+	err = suite.repo.SetCompleted(suite.ctx, suite.testTodo.ID, false, domain.TodoPatch{UpdatedAt: time.Now(), Version: 2})
+	assert.NoError(suite.T(), err)
+
+	todo, err = suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), todo.Completed)
+	assert.Nil(suite.T(), todo.CompletedAt)
+
+	err = suite.repo.SetCompleted(suite.ctx, suite.testTodo.ID, true, domain.TodoPatch{UpdatedAt: time.Now(), Version: 2})
+	assert.ErrorIs(suite.T(), err, domain.ErrStale)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestFindAll_CompletedRange() {
+	now := time.Now()
+	lastWeek, yesterday := now.AddDate(0, 0, -7), now.AddDate(0, 0, -1)
+	todos := []*domain.Todo{
+		{ID: uuid.New(), Title: "Old", Completed: true, CompletedAt: &lastWeek, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.New(), Title: "Recent", Completed: true, CompletedAt: &yesterday, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.New(), Title: "Pending", CreatedAt: now, UpdatedAt: now},
+	}
+	for _, todo := range todos {
+		suite.Require().NoError(suite.repo.Create(suite.ctx, todo))
+	}
+
+	since := now.AddDate(0, 0, -3)
+	result, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{CompletedAfter: &since})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(result, 1)
+	assert.Equal(suite.T(), "Recent", result[0].Title)
+
+	result, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{CompletedBefore: &since})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(result, 1)
+	assert.Equal(suite.T(), "Old", result[0].Title)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestArchive() {
+	now := time.Now()
+	lastMonth := now.AddDate(0, -1, 0)
+	old := &domain.Todo{ID: uuid.New(), Title: "Old", Completed: true, CompletedAt: &lastMonth, CreatedAt: now, UpdatedAt: now, Version: 1}
+	recent := &domain.Todo{ID: uuid.New(), Title: "Recent", Completed: true, CompletedAt: &now, CreatedAt: now, UpdatedAt: now, Version: 1}
+	for _, todo := range []*domain.Todo{old, recent, suite.testTodo} {
+		suite.Require().NoError(suite.repo.Create(suite.ctx, todo))
+	}
+
+	completed := true
+	before := now.AddDate(0, 0, -14)
+	archived, err := suite.repo.Archive(suite.ctx, domain.TodoQuery{Completed: &completed, CompletedBefore: &before}, now)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, archived)
+
+	// Archiving twice leaves the archived todos alone
+	archived, err = suite.repo.Archive(suite.ctx, domain.TodoQuery{Completed: &completed, CompletedBefore: &before}, now)
+	assert.NoError(suite.T(), err)
+	assert.Zero(suite.T(), archived)
+
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 2)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{Archived: true})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(todos, 1)
+	assert.Equal(suite.T(), old.ID, todos[0].ID)
+	suite.Require().NotNil(todos[0].ArchivedAt)
+	assert.WithinDuration(suite.T(), now, *todos[0].ArchivedAt, time.Microsecond)
+	assert.Equal(suite.T(), 2, todos[0].Version)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{IncludeArchived: true})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 3)
+
+	// Archived todos can still be looked up directly
+	found, err := suite.repo.FindByID(suite.ctx, old.ID)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), found.ArchivedAt)
+
+	err = suite.repo.Unarchive(suite.ctx, old.ID)
+	assert.NoError(suite.T(), err)
+
+	found, err = suite.repo.FindByID(suite.ctx, old.ID)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), found.ArchivedAt)
+
+	err = suite.repo.Unarchive(suite.ctx, old.ID)
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestEvents() {
+	suite.Require().NoError(suite.repo.Create(suite.ctx, suite.testTodo))
+
+	created := domain.NewTodoEvent(domain.WithActor(suite.ctx, "ana"), domain.EventCreated, nil, suite.testTodo)
+	err := suite.repo.AddEvent(suite.ctx, created)
+	assert.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), created.ID)
+
+	// A failed write takes its event down with it
+	failed := errors.New("failed")
+	err = suite.repo.Atomically(suite.ctx, func(repo domain.TodoRepository) error {
+		if err := repo.AddEvent(suite.ctx, domain.NewTodoEvent(suite.ctx, domain.EventDeleted, suite.testTodo, nil)); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(suite.T(), err, failed)
+
+	events, err := suite.repo.FindEvents(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(events, 1)
+	assert.Equal(suite.T(), created.ID, events[0].ID)
+	assert.Equal(suite.T(), domain.EventCreated, events[0].Action)
+	assert.Equal(suite.T(), "ana", events[0].Actor)
+	assert.Equal(suite.T(), created.Changes, events[0].Changes)
+	assert.WithinDuration(suite.T(), created.OccurredAt, events[0].OccurredAt, time.Microsecond)
+
+	// Purging a todo drops its history
+	suite.Require().NoError(suite.repo.Delete(suite.ctx, suite.testTodo.ID, time.Now().Add(-time.Hour)))
+	_, err = suite.repo.Purge(suite.ctx, time.Now())
+	suite.Require().NoError(err)
+
+	events, err = suite.repo.FindEvents(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), events)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestBatch() {
+	now := time.Now()
+	other := &domain.Todo{ID: uuid.New(), Title: "Other", CreatedAt: now, UpdatedAt: now, Version: 1}
+	subtask := &domain.Todo{ID: uuid.New(), Title: "Subtask", ParentID: &other.ID, CreatedAt: now, UpdatedAt: now, Version: 1}
+	for _, todo := range []*domain.Todo{suite.testTodo, other, subtask} {
+		suite.Require().NoError(suite.repo.Create(suite.ctx, todo))
+	}
+	both := []*domain.Todo{suite.testTodo, other}
+
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{IDs: []uuid.UUID{suite.testTodo.ID, other.ID}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 2)
+
+	err = suite.repo.SetCompletedMany(suite.ctx, both, true, domain.TodoPatch{UpdatedAt: now})
+	assert.NoError(suite.T(), err)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{IDs: todoIDs(both)})
+	assert.NoError(suite.T(), err)
+	for _, todo := range todos {
+		assert.True(suite.T(), todo.Completed)
+		assert.Equal(suite.T(), 2, todo.Version)
+	}
+
+	// The todos are still at version 1 here, so nothing is written
+	title := "Renamed"
+	err = suite.repo.UpdateMany(suite.ctx, both, domain.TodoPatch{Title: &title, UpdatedAt: now})
+	assert.ErrorIs(suite.T(), err, domain.ErrStale)
+
+	found, err := suite.repo.FindByID(suite.ctx, other.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Other", found.Title)
+
+	err = suite.repo.AddTagsMany(suite.ctx, []uuid.UUID{suite.testTodo.ID, other.ID}, []string{"bulk", "sprint-12"})
+	assert.NoError(suite.T(), err)
+	err = suite.repo.RemoveTagsMany(suite.ctx, []uuid.UUID{other.ID}, []string{"bulk"})
+	assert.NoError(suite.T(), err)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{Tags: []string{"sprint-12"}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 2)
+	found, err = suite.repo.FindByID(suite.ctx, other.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []string{"sprint-12"}, found.Tags)
+
+	// Deleting takes the subtasks along, stamped alike so they are restored
+	// together
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{IDs: todoIDs(both)})
+	suite.Require().NoError(err)
+	err = suite.repo.DeleteMany(suite.ctx, todos, now)
+	assert.NoError(suite.T(), err)
+
+	trashed, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Trashed: true})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), trashed, 3)
+
+	suite.Require().NoError(suite.repo.Restore(suite.ctx, other.ID))
+	_, err = suite.repo.FindByID(suite.ctx, subtask.ID)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestJournal() {
+	suite.Require().NoError(suite.repo.Create(suite.ctx, suite.testTodo))
+
+	// Two operations by ana, the second of two events, and one by bob
+	ana := domain.WithActor(suite.ctx, "ana")
+	first, second := domain.WithOperation(ana), domain.WithOperation(ana)
+	add := func(ctx context.Context, action domain.EventAction) *domain.TodoEvent {
+		event := domain.NewTodoEvent(ctx, action, suite.testTodo, suite.testTodo)
+		suite.Require().NoError(suite.repo.AddEvent(suite.ctx, event))
+		return event
+	}
+	created := add(first, domain.EventCreated)
+	updated, completed := add(second, domain.EventUpdated), add(second, domain.EventCompleted)
+	add(domain.WithActor(suite.ctx, "bob"), domain.EventUpdated)
+
+	undoable, err := suite.repo.FindUndoable(suite.ctx, "ana", 5)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(undoable, 2)
+	assert.Equal(suite.T(), updated.Operation, undoable[0].ID)
+	suite.Require().Len(undoable[0].Events, 2)
+	assert.Equal(suite.T(), []int64{updated.ID, completed.ID}, []int64{undoable[0].Events[0].ID, undoable[0].Events[1].ID})
+	assert.Equal(suite.T(), created.Operation, undoable[1].ID)
+
+	undoable, err = suite.repo.FindUndoable(suite.ctx, "ana", 1)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), undoable, 1)
+
+	// Undone operations are redone in the order they were made
+	undoneAt := time.Now()
+	suite.Require().NoError(suite.repo.SetUndone(suite.ctx, updated.Operation, &undoneAt))
+	suite.Require().NoError(suite.repo.SetUndone(suite.ctx, created.Operation, &undoneAt))
+
+	undoable, err = suite.repo.FindUndoable(suite.ctx, "ana", 5)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), undoable)
+
+	redoable, err := suite.repo.FindRedoable(suite.ctx, "ana", 5)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(redoable, 2)
+	assert.Equal(suite.T(), created.Operation, redoable[0].ID)
+	assert.Equal(suite.T(), updated.Operation, redoable[1].ID)
+	suite.Require().NotNil(redoable[0].Events[0].UndoneAt)
+	assert.WithinDuration(suite.T(), undoneAt, *redoable[0].Events[0].UndoneAt, time.Microsecond)
+
+	// A new change by ana leaves only what was undone after it to redo
+	suite.Require().NoError(suite.repo.SetUndone(suite.ctx, created.Operation, nil))
+	redoable, err = suite.repo.FindRedoable(suite.ctx, "ana", 5)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(redoable, 1)
+	assert.Equal(suite.T(), updated.Operation, redoable[0].ID)
+
+	add(ana, domain.EventUpdated)
+	redoable, err = suite.repo.FindRedoable(suite.ctx, "ana", 5)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), redoable)
+
+	err = suite.repo.SetUndone(suite.ctx, uuid.New(), nil)
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestDeleteTodo() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	deletedAt := time.Now().Add(-time.Hour)
+	err = suite.repo.Delete(suite.ctx, suite.testTodo.ID, deletedAt)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+
+	// Deleted todos cannot be changed, only restored
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{UpdatedAt: time.Now(), Version: 2})
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+
+	trashed, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Trashed: true})
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(trashed, 1)
+	suite.Require().NotNil(trashed[0].DeletedAt)
+	assert.WithinDuration(suite.T(), deletedAt, *trashed[0].DeletedAt, time.Microsecond)
+
+	id, err := suite.repo.ResolveID(suite.ctx, suite.testTodo.ID.String()[:8], domain.TodoQuery{Trashed: true})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.testTodo.ID, id)
+
+	// Only todos deleted before the cutoff are purged
+	purged, err := suite.repo.Purge(suite.ctx, deletedAt.Add(-time.Minute))
+	assert.NoError(suite.T(), err)
+	assert.Zero(suite.T(), purged)
+
+	purged, err = suite.repo.Purge(suite.ctx, time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, purged)
+
+	err = suite.repo.Restore(suite.ctx, suite.testTodo.ID)
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *SQLiteTodoRepositoryTestSuite) TestDeleteTodo_NotFound() {
+	err := suite.repo.Delete(suite.ctx, uuid.New(), time.Now())
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+
+	err = suite.repo.Restore(suite.ctx, uuid.New())
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func TestSQLiteTodoRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteTodoRepositoryTestSuite))
+}
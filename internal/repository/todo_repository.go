@@ -2,33 +2,82 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
 )
 
 type TodoRepository struct {
-	db *pgxpool.Pool
+	db pgxHandle
+}
+
+// pgxHandle is what the repositories need from a pool, or from the
+// transaction Atomically runs in.
+type pgxHandle interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 func NewTodoRepository(db *pgxpool.Pool) *TodoRepository {
 	return &TodoRepository{db: db}
 }
 
-func (r *TodoRepository) FindAll(ctx context.Context) ([]*domain.Todo, error) {
-	query := `
-			SELECT 
-    			id, 
-    			title, 
-    			description, 
-    			completed, 
-    			created_at, 
-				updated_at 
-			FROM todos
-			ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(ctx, query)
+// todoColumns lists the columns scanTodo expects, in order.
+const todoColumns = `
+				id,
+				title,
+				description,
+				completed,
+				priority,
+				due_date,
+				project_id,
+				parent_id,
+				(
+					SELECT COUNT(*) FROM todos sub
+					WHERE sub.parent_id = todos.id AND sub.completed AND sub.deleted_at IS NULL
+				) AS subtasks_done,
+				(SELECT COUNT(*) FROM todos sub WHERE sub.parent_id = todos.id AND sub.deleted_at IS NULL) AS subtasks_total,
+				(
+					SELECT COUNT(*) FROM todo_dependencies d JOIN todos blocker ON blocker.id = d.blocker_id
+					WHERE d.todo_id = todos.id AND NOT blocker.completed AND blocker.deleted_at IS NULL
+				) AS open_blockers,
+				recurrence,
+				created_at,
+				updated_at,
+				version,
+				completed_at,
+				deleted_at,
+				archived_at`
+
+const selectTodos = `
+			SELECT` + todoColumns + `,
+				ARRAY(
+					SELECT t.name FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+					WHERE tt.todo_id = todos.id
+					ORDER BY t.name
+				) AS tags
+			FROM todos`
+
+func (r *TodoRepository) FindAll(ctx context.Context, q domain.TodoQuery) ([]*domain.Todo, error) {
+	b := &queryBuilder{dialect: postgres}
+	filterTodos(b, q)
+
+	query := selectTodos + `
+			` + b.whereClause() + `
+			` + orderByClause(q.Sort) + `
+			` + limitClause(b, q)
+
+	rows, err := r.db.Query(ctx, query, b.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,21 +85,12 @@ func (r *TodoRepository) FindAll(ctx context.Context) ([]*domain.Todo, error) {
 
 	var todos []*domain.Todo
 	for rows.Next() {
-		var todo domain.Todo
-
-		err := rows.Scan(
-			&todo.ID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-		)
+		todo, err := scanTodo(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		todos = append(todos, &todo)
+		todos = append(todos, todo)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -61,61 +101,527 @@ func (r *TodoRepository) FindAll(ctx context.Context) ([]*domain.Todo, error) {
 }
 
 func (r *TodoRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
-	query := `
-			SELECT
-				id, 
-    			title, 
-    			description, 
-    			completed, 
-    			created_at, 
-				updated_at 
-			FROM todos
-			WHERE id = $1
+	query := selectTodos + `
+			WHERE id = $1 AND deleted_at IS NULL
 	`
-	var todo domain.Todo
+	todo, err := scanTodo(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, pgError(err, "todo "+id.String())
+	}
+	return todo, nil
+}
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-	)
+func (r *TodoRepository) ResolveID(ctx context.Context, ref string, scope domain.TodoQuery) (uuid.UUID, error) {
+	return resolveID(ctx, r, ref, scope)
+}
+
+// idPrefix only admits characters that can appear in a UUID, which also keeps
+// LIKE wildcards out of the lookup.
+var idPrefix = regexp.MustCompile(`^[0-9a-f-]+$`)
+
+// resolveID accepts a full ID as is and otherwise looks ref up as an ID
+// prefix, which has to match exactly one of the todos scope matches.
+func resolveID(ctx context.Context, repo domain.TodoRepository, ref string, scope domain.TodoQuery) (uuid.UUID, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, nil
+	}
+	if !idPrefix.MatchString(ref) || len(ref) > 36 {
+		return uuid.Nil, fmt.Errorf("%w: %q is not a todo id", domain.ErrValidation, ref)
+	}
+
+	scope.IDPrefix = ref
+	todos, err := repo.FindAll(ctx, scope)
 	if err != nil {
-		return nil, err
+		return uuid.Nil, err
 	}
 
-	return &todo, nil
+	switch len(todos) {
+	case 0:
+		return uuid.Nil, fmt.Errorf("todo %s: %w", ref, domain.ErrNotFound)
+	case 1:
+		return todos[0].ID, nil
+	default:
+		return uuid.Nil, &domain.AmbiguousIDError{Prefix: ref, Candidates: todos}
+	}
 }
 
 func (r *TodoRepository) Create(ctx context.Context, todo *domain.Todo) error {
 	query := `
-			INSERT INTO todos (id, title, description, completed, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6)
+			INSERT INTO todos (id, title, description, completed, completed_at, priority, due_date, project_id, parent_id, recurrence, created_at, updated_at, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query,
+			todo.ID, todo.Title, todo.Description, todo.Completed, todo.CompletedAt, todo.Priority, todo.DueDate, todo.ProjectID,
+			todo.ParentID, todo.Recurrence, todo.CreatedAt, todo.UpdatedAt, todo.Version)
+		if err != nil {
+			return err
+		}
+
+		return addTags(ctx, tx, todo.ID, todo.Tags)
+	})
+	return pgError(err, "todo "+todo.ID.String())
+}
+
+func (r *TodoRepository) Update(ctx context.Context, id uuid.UUID, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: postgres}
+	return r.update(ctx, b, setClause(b, patch), id, patch.Version)
+}
+
+func (r *TodoRepository) SetCompleted(ctx context.Context, id uuid.UUID, completed bool, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: postgres}
+	return r.update(ctx, b, setClause(b, patch)+completionClause(b, completed, patch.UpdatedAt), id, patch.Version)
+}
+
+// update runs an UPDATE of todo id with the SET clause set, whose arguments
+// are already bound to b, as long as the todo is still at version.
+func (r *TodoRepository) update(ctx context.Context, b *queryBuilder, set string, id uuid.UUID, version int) error {
+	query := `
+			UPDATE todos
+			` + set + `
+			WHERE id = ` + b.arg(id) + ` AND version = ` + b.arg(version) + ` AND deleted_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, b.args...)
+	if err != nil {
+		return pgError(err, "todo "+id.String())
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Nothing matched: either the todo is gone or someone else got there first
+	var current int
+	err = r.db.QueryRow(ctx, `SELECT version FROM todos WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&current)
+	if err != nil {
+		return pgError(err, "todo "+id.String())
+	}
+	return staleTodoError(id, current)
+}
+
+func (r *TodoRepository) UpdateMany(ctx context.Context, todos []*domain.Todo, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: postgres}
+	return r.updateMany(ctx, b, setClause(b, patch), todos)
+}
+
+func (r *TodoRepository) SetCompletedMany(ctx context.Context, todos []*domain.Todo, completed bool, patch domain.TodoPatch) error {
+	b := &queryBuilder{dialect: postgres}
+	return r.updateMany(ctx, b, setClause(b, patch)+completionClause(b, completed, patch.UpdatedAt), todos)
+}
+
+// updateMany is update for every one of todos, at the version each has
+// there.
+func (r *TodoRepository) updateMany(ctx context.Context, b *queryBuilder, set string, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	query := `
+			UPDATE todos
+			` + set + `
+			WHERE ` + versionClause(b, todos) + ` AND deleted_at IS NULL`
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, b.args...)
+		if err != nil {
+			return pgError(err, "todos")
+		}
+		if n := tag.RowsAffected(); n < int64(len(todos)) {
+			return staleBatchError(n, len(todos))
+		}
+		return nil
+	})
+}
+
+func (r *TodoRepository) Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	query := `
+			UPDATE todos
+			SET deleted_at = $2, version = version + 1
+			WHERE deleted_at IS NULL AND id IN (
+				WITH RECURSIVE subtree(id) AS (
+					SELECT $1::uuid
+					UNION
+					SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			)
+	`
+	tag, err := r.db.Exec(ctx, query, id, deletedAt)
+	if err != nil {
+		return pgError(err, "todo "+id.String())
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("todo %s: %w", id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *TodoRepository) DeleteMany(ctx context.Context, todos []*domain.Todo, deletedAt time.Time) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	b := &queryBuilder{dialect: postgres}
+	query := `
+			UPDATE todos
+			SET deleted_at = ` + b.arg(deletedAt) + `, version = version + 1
+			WHERE ` + versionClause(b, todos) + ` AND deleted_at IS NULL`
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, b.args...)
+		if err != nil {
+			return err
+		}
+		if n := tag.RowsAffected(); n < int64(len(todos)) {
+			return staleBatchError(n, len(todos))
+		}
+
+		subtrees := &queryBuilder{dialect: postgres}
+		_, err = tx.Exec(ctx, deleteSubtreesQuery(subtrees, todoIDs(todos), deletedAt), subtrees.args...)
+		return err
+	})
+	return pgError(err, "todos")
+}
+
+// Restore matches the subtasks deleted along with the todo by their
+// deleted_at, which Delete stamps on the whole subtree at once.
+func (r *TodoRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `
+			UPDATE todos
+			SET deleted_at = NULL, version = version + 1
+			WHERE deleted_at = (SELECT deleted_at FROM todos WHERE id = $1) AND id IN (
+				WITH RECURSIVE subtree(id) AS (
+					SELECT $1::uuid
+					UNION
+					SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			)
 	`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return pgError(err, "todo "+id.String())
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("todo %s in the trash: %w", id, domain.ErrNotFound)
+	}
+	return nil
+}
 
-	_, err := r.db.Exec(ctx, query,
-		todo.ID, todo.Title, todo.Description, todo.Completed, todo.CreatedAt, todo.UpdatedAt)
-	return err
+func (r *TodoRepository) Archive(ctx context.Context, q domain.TodoQuery, archivedAt time.Time) (int, error) {
+	b := &queryBuilder{dialect: postgres}
+	set := "SET archived_at = " + b.arg(archivedAt) + ", version = version + 1"
+
+	// Only todos that are not archived yet
+	q.Archived, q.IncludeArchived, q.Trashed = false, false, false
+	filterTodos(b, q)
+
+	tag, err := r.db.Exec(ctx, "UPDATE todos "+set+" "+b.whereClause(), b.args...)
+	if err != nil {
+		return 0, pgError(err, "todos")
+	}
+	return int(tag.RowsAffected()), nil
 }
 
-func (r *TodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+func (r *TodoRepository) Unarchive(ctx context.Context, id uuid.UUID) error {
 	query := `
 			UPDATE todos
-			SET title = $1, description = $2, updated_at = $3
-			WHERE id = $4
+			SET archived_at = NULL, version = version + 1
+			WHERE id = $1 AND archived_at IS NOT NULL AND deleted_at IS NULL
 	`
-	_, err := r.db.Exec(ctx, query,
-		todo.Title, todo.Description, todo.UpdatedAt, todo.ID)
-	return err
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return pgError(err, "todo "+id.String())
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("archived todo %s: %w", id, domain.ErrNotFound)
+	}
+	return nil
 }
 
-func (r *TodoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// Purge removes the todos for good; subtasks purged alongside their parent
+// go through ON DELETE CASCADE.
+func (r *TodoRepository) Purge(ctx context.Context, before time.Time) (int, error) {
 	query := `
 			DELETE FROM todos
-			WHERE id = $1
+			WHERE deleted_at < $1
 	`
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+	tag, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, pgError(err, "todos")
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *TodoRepository) AddTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return addTags(ctx, tx, id, tags)
+	})
+	return pgError(err, "todo "+id.String())
+}
+
+func addTags(ctx context.Context, tx pgx.Tx, id uuid.UUID, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO tags (name)
+			VALUES ($1)
+			ON CONFLICT (name) DO NOTHING
+		`, tag)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO todo_tags (todo_id, tag_id)
+			SELECT $1, id FROM tags WHERE name = $2
+			ON CONFLICT DO NOTHING
+		`, id, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *TodoRepository) RemoveTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	query := `
+			DELETE FROM todo_tags
+			WHERE todo_id = $1 AND tag_id IN (SELECT id FROM tags WHERE name = ANY($2))
+	`
+	_, err := r.db.Exec(ctx, query, id, tags)
+	return pgError(err, "todo "+id.String())
+}
+
+func (r *TodoRepository) AddTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO tags (name)
+			SELECT unnest($1::text[])
+			ON CONFLICT (name) DO NOTHING
+		`, tags)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO todo_tags (todo_id, tag_id)
+			SELECT todo.id, tag.id
+			FROM unnest($1::uuid[]) AS todo(id)
+			CROSS JOIN tags tag
+			WHERE tag.name = ANY($2)
+			ON CONFLICT DO NOTHING
+		`, ids, tags)
+		return err
+	})
+	return pgError(err, "todos")
+}
+
+func (r *TodoRepository) RemoveTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error {
+	query := `
+			DELETE FROM todo_tags
+			WHERE todo_id = ANY($1) AND tag_id IN (SELECT id FROM tags WHERE name = ANY($2))
+	`
+	_, err := r.db.Exec(ctx, query, ids, tags)
+	return pgError(err, "todos")
+}
+
+func (r *TodoRepository) FindAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	query := `
+			SELECT t.name, COUNT(*)
+			FROM tags t
+			JOIN todo_tags tt ON tt.tag_id = t.id
+			JOIN todos ON todos.id = tt.todo_id AND todos.deleted_at IS NULL AND todos.archived_at IS NULL
+			GROUP BY t.name
+			ORDER BY COUNT(*) DESC, t.name
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*domain.TagCount
+	for rows.Next() {
+		var tag domain.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *TodoRepository) AddBlocker(ctx context.Context, id, blockerID uuid.UUID) error {
+	query := `
+			INSERT INTO todo_dependencies (todo_id, blocker_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Exec(ctx, query, id, blockerID)
+	return pgError(err, "todo "+id.String())
+}
+
+func (r *TodoRepository) RemoveBlocker(ctx context.Context, id, blockerID uuid.UUID) error {
+	query := `
+			DELETE FROM todo_dependencies
+			WHERE todo_id = $1 AND blocker_id = $2
+	`
+	_, err := r.db.Exec(ctx, query, id, blockerID)
+	return pgError(err, "todo "+id.String())
+}
+
+func (r *TodoRepository) AddEvent(ctx context.Context, event *domain.TodoEvent) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+			INSERT INTO todo_events (todo_id, action, changes, actor, occurred_at, operation)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+	`
+	err = r.db.QueryRow(ctx, query, event.TodoID, event.Action, changes, event.Actor, event.OccurredAt, event.Operation).Scan(&event.ID)
+	return pgError(err, "todo "+event.TodoID.String())
+}
+
+func (r *TodoRepository) FindEvents(ctx context.Context, id uuid.UUID) ([]*domain.TodoEvent, error) {
+	query := `
+			SELECT ` + eventColumns + `
+			FROM todo_events e
+			WHERE e.todo_id = $1
+			ORDER BY e.id
+	`
+	return r.findEvents(ctx, query, id)
+}
+
+func (r *TodoRepository) FindUndoable(ctx context.Context, actor string, limit int) ([]*domain.Operation, error) {
+	b := &queryBuilder{dialect: postgres}
+	events, err := r.findEvents(ctx, journalQuery(b, actor, false, limit), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	return groupOperations(events), nil
+}
+
+func (r *TodoRepository) FindRedoable(ctx context.Context, actor string, limit int) ([]*domain.Operation, error) {
+	b := &queryBuilder{dialect: postgres}
+	events, err := r.findEvents(ctx, journalQuery(b, actor, true, limit), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	return groupOperations(events), nil
+}
+
+func (r *TodoRepository) findEvents(ctx context.Context, query string, args ...any) ([]*domain.TodoEvent, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.TodoEvent
+	for rows.Next() {
+		var (
+			event   domain.TodoEvent
+			changes []byte
+		)
+		err := rows.Scan(
+			&event.ID,
+			&event.TodoID,
+			&event.Action,
+			&changes,
+			&event.Actor,
+			&event.OccurredAt,
+			&event.Operation,
+			&event.UndoneAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *TodoRepository) SetUndone(ctx context.Context, operation uuid.UUID, undoneAt *time.Time) error {
+	tag, err := r.db.Exec(ctx, `UPDATE todo_events SET undone_at = $2 WHERE operation = $1`, operation, undoneAt)
+	if err != nil {
+		return pgError(err, "operation "+operation.String())
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("operation %s: %w", operation, domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *TodoRepository) Atomically(ctx context.Context, fn func(repo domain.TodoRepository) error) error {
+	return pgTx(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&TodoRepository{db: tx})
+	})
+}
+
+// serializableAttempts is how often pgTx runs a transaction that
+// PostgreSQL keeps aborting to stay serializable.
+const serializableAttempts = 3
+
+// pgTx runs fn in a serializable transaction, committing only if it
+// succeeds, so nothing fn read can change before its writes commit. When
+// PostgreSQL aborts the transaction because a concurrent one got there
+// first, fn runs again from the start. Inside Atomically, fn joins the
+// transaction that is already open in a savepoint.
+func pgTx(ctx context.Context, db pgxHandle, fn func(tx pgx.Tx) error) error {
+	if tx, ok := db.(pgx.Tx); ok {
+		return pgx.BeginFunc(ctx, tx, fn)
+	}
+
+	options := pgx.TxOptions{IsoLevel: pgx.Serializable}
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, db.(*pgxpool.Pool), options, fn)
+		if !serializationFailure(err) {
+			return err
+		}
+		if attempt == serializableAttempts {
+			return fmt.Errorf("%w by a concurrent transaction", domain.ErrStale)
+		}
+	}
+}
+
+func scanTodo(row pgx.Row) (*domain.Todo, error) {
+	var todo domain.Todo
+
+	err := row.Scan(
+		&todo.ID,
+		&todo.Title,
+		&todo.Description,
+		&todo.Completed,
+		&todo.Priority,
+		&todo.DueDate,
+		&todo.ProjectID,
+		&todo.ParentID,
+		&todo.Subtasks.Done,
+		&todo.Subtasks.Total,
+		&todo.OpenBlockers,
+		&todo.Recurrence,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+		&todo.Version,
+		&todo.CompletedAt,
+		&todo.DeletedAt,
+		&todo.ArchivedAt,
+		&todo.Tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
 }
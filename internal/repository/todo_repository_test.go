@@ -2,12 +2,15 @@ package repository
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/migrate"
+	"github.com/leandrowiemesfilho/go-todo-cli/migrations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -33,23 +36,16 @@ func (suite *TodoRepositoryTestSuite) SetupSuite() {
 
 	suite.repo = NewTodoRepository(suite.pool)
 
-	// Create test table
-	_, err = suite.pool.Exec(suite.ctx, `
-		CREATE TABLE IF NOT EXISTS todos_test (
-			id UUID PRIMARY KEY,
-			title VARCHAR(255) NOT NULL,
-			description TEXT,
-			completed BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-		);
-    `)
-	assert.NoError(suite.T(), err)
+	// Bring the test database schema up to date
+	schema, err := migrate.Load(migrations.Postgres, "postgres")
+	suite.Require().NoError(err)
+	_, err = migrate.New(migrate.NewPostgresDriver(suite.pool), schema).Up(suite.ctx)
+	suite.Require().NoError(err)
 }
 
 func (suite *TodoRepositoryTestSuite) SetupTest() {
-	// Clear the test table before each test
-	_, err := suite.pool.Exec(suite.ctx, "TRUNCATE TABLE todos_test RESTART IDENTITY")
+	// Clear the test tables before each test
+	_, err := suite.pool.Exec(suite.ctx, "TRUNCATE TABLE todos, todo_tags, tags, todo_dependencies, todo_events, projects RESTART IDENTITY CASCADE")
 	assert.NoError(suite.T(), err)
 
 	// Create a test TODO
@@ -60,10 +56,11 @@ func (suite *TodoRepositoryTestSuite) SetupTest() {
 		Completed:   false,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+		Version:     1,
 	}
 }
 
-func (suite *TodoRepositoryTestSuite) TearDownTest() {
+func (suite *TodoRepositoryTestSuite) TearDownSuite() {
 	if suite.pool != nil {
 		suite.pool.Close()
 	}
@@ -95,7 +92,7 @@ func (suite *TodoRepositoryTestSuite) TestFindAll() {
 		assert.NoError(suite.T(), err)
 	}
 
-	foundTodos, err := suite.repo.FindAll(suite.ctx)
+	foundTodos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{})
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), len(todos), len(foundTodos))
 }
@@ -115,7 +112,7 @@ func (suite *TodoRepositoryTestSuite) TestFindById_NotFound() {
 	// Try to find an nonexistent TODO
 	nonExistentID := uuid.New()
 	_, err := suite.repo.FindByID(suite.ctx, nonExistentID)
-	assert.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
 }
 
 func (suite *TodoRepositoryTestSuite) TestCreateTodo() {
@@ -127,7 +124,7 @@ func (suite *TodoRepositoryTestSuite) TestCreateTodo() {
 	suite.pool.QueryRow(suite.ctx, `
 		SELECT 
 		    COUNT(*) 
-		FROM todos_test 
+		FROM todos 
 		WHERE id = $1
 	`, suite.testTodo.ID).Scan(&count)
 	assert.Equal(suite.T(), 1, count)
@@ -135,11 +132,11 @@ func (suite *TodoRepositoryTestSuite) TestCreateTodo() {
 
 func (suite *TodoRepositoryTestSuite) TestCreateTodo_DuplicatedID() {
 	err := suite.repo.Create(suite.ctx, suite.testTodo)
-	assert.Error(suite.T(), err)
+	assert.NoError(suite.T(), err)
 
 	// Try to create a TODO with the same ID
 	err = suite.repo.Create(suite.ctx, suite.testTodo)
-	assert.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, domain.ErrConflict)
 }
 
 func (suite *TodoRepositoryTestSuite) TestUpdateTodo() {
@@ -148,30 +145,188 @@ func (suite *TodoRepositoryTestSuite) TestUpdateTodo() {
 	assert.NoError(suite.T(), err)
 
 	// Update TODO information
-	suite.testTodo.Description = "Updated description"
-	suite.testTodo.UpdatedAt = time.Now()
-
-	err = suite.repo.Update(suite.ctx, suite.testTodo)
+	description := "Updated description"
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{Description: &description, UpdatedAt: time.Now(), Version: 1})
 	assert.NoError(suite.T(), err)
 
 	// Validate TODO was updated
 	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), todo.Description, suite.testTodo.Description)
+	assert.Equal(suite.T(), description, todo.Description)
+	assert.Equal(suite.T(), suite.testTodo.Title, todo.Title)
 }
 
 func (suite *TodoRepositoryTestSuite) TestUpdateTodo_NotFound() {
-	nonExistentTodo := &domain.Todo{
-		ID:          uuid.New(),
-		Title:       "Non-existent",
-		Description: "Should not exists",
-		Completed:   false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	title := "Non-existent"
+	err := suite.repo.Update(suite.ctx, uuid.New(), domain.TodoPatch{Title: &title, UpdatedAt: time.Now(), Version: 1})
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *TodoRepositoryTestSuite) TestUpdateTodo_Stale() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	// Two writers start from version 1; only the first one wins
+	first, second := "First writer", "Second writer"
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{Title: &first, UpdatedAt: time.Now(), Version: 1})
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.Update(suite.ctx, suite.testTodo.ID, domain.TodoPatch{Title: &second, UpdatedAt: time.Now(), Version: 1})
+	assert.ErrorIs(suite.T(), err, domain.ErrStale)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first, todo.Title)
+	assert.Equal(suite.T(), 2, todo.Version)
+}
+
+func (suite *TodoRepositoryTestSuite) TestAtomically_Concurrent() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	suite.Require().NoError(err)
+
+	// Both units of work read the title before either writes it; the one
+	// that loses the race runs again on top of the other's change
+	var read, done sync.WaitGroup
+	read.Add(2)
+	for _, suffix := range []string{" A", " B"} {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			attempt := 0
+			err := suite.repo.Atomically(suite.ctx, func(repo domain.TodoRepository) error {
+				todo, err := repo.FindByID(suite.ctx, suite.testTodo.ID)
+				if err != nil {
+					return err
+				}
+				if attempt++; attempt == 1 {
+					read.Done()
+					read.Wait()
+				}
+				title := todo.Title + suffix
+				return repo.Update(suite.ctx, todo.ID, domain.TodoPatch{Title: &title, UpdatedAt: time.Now(), Version: todo.Version})
+			})
+			assert.NoError(suite.T(), err)
+		}()
 	}
+	done.Wait()
 
-	err := suite.repo.Update(suite.ctx, nonExistentTodo)
-	assert.Error(suite.T(), err)
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todo.Title, len(suite.testTodo.Title+" A B"))
+	assert.Equal(suite.T(), 3, todo.Version)
+}
+
+func (suite *TodoRepositoryTestSuite) TestSetCompleted() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	completedAt := time.Now()
+	err = suite.repo.SetCompleted(suite.ctx, suite.testTodo.ID, true, domain.TodoPatch{UpdatedAt: completedAt, Version: 1})
+	assert.NoError(suite.T(), err)
+
+	todo, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), todo.Completed)
+	suite.Require().NotNil(todo.CompletedAt)
+	assert.WithinDuration(suite.T(), completedAt, *todo.CompletedAt, time.Millisecond)
+
+	// Reopening clears the completion time
+	err = suite.repo.SetCompleted(suite.ctx, suite.testTodo.ID, false, domain.TodoPatch{UpdatedAt: time.Now(), Version: 2})
+	assert.NoError(suite.T(), err)
+
+	todo, err = suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), todo.Completed)
+	assert.Nil(suite.T(), todo.CompletedAt)
+}
+
+func (suite *TodoRepositoryTestSuite) TestArchive() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	archived, err := suite.repo.Archive(suite.ctx, domain.TodoQuery{IDPrefix: suite.testTodo.ID.String()}, time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, archived)
+
+	// Archived TODOs are left out unless asked for
+	todos, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), todos)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{Archived: true})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 1)
+
+	err = suite.repo.Unarchive(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+
+	todos, err = suite.repo.FindAll(suite.ctx, domain.TodoQuery{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), todos, 1)
+}
+
+func (suite *TodoRepositoryTestSuite) TestEvents() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.Atomically(suite.ctx, func(repo domain.TodoRepository) error {
+		return repo.AddEvent(suite.ctx, domain.NewTodoEvent(suite.ctx, domain.EventCreated, nil, suite.testTodo))
+	})
+	assert.NoError(suite.T(), err)
+
+	events, err := suite.repo.FindEvents(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), events, 1)
+	assert.Equal(suite.T(), domain.EventCreated, events[0].Action)
+	assert.NotEmpty(suite.T(), events[0].Changes)
+}
+
+func (suite *TodoRepositoryTestSuite) TestBatch() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+	todos := []*domain.Todo{suite.testTodo}
+
+	err = suite.repo.SetCompletedMany(suite.ctx, todos, true, domain.TodoPatch{UpdatedAt: time.Now()})
+	assert.NoError(suite.T(), err)
+
+	// The batch was based on the version before completing
+	err = suite.repo.DeleteMany(suite.ctx, todos, time.Now())
+	assert.ErrorIs(suite.T(), err, domain.ErrStale)
+
+	err = suite.repo.AddTagsMany(suite.ctx, []uuid.UUID{suite.testTodo.ID}, []string{"bulk"})
+	assert.NoError(suite.T(), err)
+
+	found, err := suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	suite.Require().NoError(err)
+	assert.True(suite.T(), found.Completed)
+	assert.Equal(suite.T(), []string{"bulk"}, found.Tags)
+
+	err = suite.repo.DeleteMany(suite.ctx, []*domain.Todo{found}, time.Now())
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TodoRepositoryTestSuite) TestJournal() {
+	err := suite.repo.Create(suite.ctx, suite.testTodo)
+	assert.NoError(suite.T(), err)
+
+	ctx := domain.WithOperation(domain.WithActor(suite.ctx, "ana"))
+	event := domain.NewTodoEvent(ctx, domain.EventCreated, nil, suite.testTodo)
+	suite.Require().NoError(suite.repo.AddEvent(suite.ctx, event))
+
+	undoable, err := suite.repo.FindUndoable(suite.ctx, "ana", 1)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(undoable, 1)
+	assert.Equal(suite.T(), event.Operation, undoable[0].ID)
+
+	// Once undone it moves over to the redo side
+	undoneAt := time.Now()
+	err = suite.repo.SetUndone(suite.ctx, event.Operation, &undoneAt)
+	assert.NoError(suite.T(), err)
+
+	redoable, err := suite.repo.FindRedoable(suite.ctx, "ana", 1)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(redoable, 1)
+	assert.NotNil(suite.T(), redoable[0].Events[0].UndoneAt)
 }
 
 func (suite *TodoRepositoryTestSuite) TestDeleteTodo() {
@@ -179,18 +334,36 @@ func (suite *TodoRepositoryTestSuite) TestDeleteTodo() {
 	err := suite.repo.Create(suite.ctx, suite.testTodo)
 
 	// Delete TODO
-	err = suite.repo.Delete(suite.ctx, suite.testTodo.ID)
+	err = suite.repo.Delete(suite.ctx, suite.testTodo.ID, time.Now())
 	assert.NoError(suite.T(), err)
 
-	// Validate TODO was deleted
+	// Validate TODO was moved to the trash
 	_, err = suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
 	assert.Error(suite.T(), err)
+
+	trashed, err := suite.repo.FindAll(suite.ctx, domain.TodoQuery{Trashed: true})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), trashed, 1)
+
+	// Restore it, then delete and purge it for good
+	err = suite.repo.Restore(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repo.FindByID(suite.ctx, suite.testTodo.ID)
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.Delete(suite.ctx, suite.testTodo.ID, time.Now())
+	assert.NoError(suite.T(), err)
+
+	purged, err := suite.repo.Purge(suite.ctx, time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, purged)
 }
 
 func (suite *TodoRepositoryTestSuite) TestDeleteTodo_NotFound() {
 	nonExistentID := uuid.New()
-	err := suite.repo.Delete(suite.ctx, nonExistentID)
-	assert.Error(suite.T(), err)
+	err := suite.repo.Delete(suite.ctx, nonExistentID, time.Now())
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
 }
 
 func TestTodoRepositoryTestSuite(t *testing.T) {
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+// bulkChange is a todo a bulk request changes, before and after.
+type bulkChange struct {
+	before, after *domain.Todo
+	// event is what the change adds to the history, empty for changes it
+	// records as part of another, such as a subtask deleted with its parent.
+	event domain.EventAction
+}
+
+// BulkUpdate works out the change to every selected todo before writing
+// any of them, one batch per kind of change, so a dry run is the same work
+// minus the writes.
+func (s todoServiceImpl) BulkUpdate(ctx context.Context, request domain.BulkRequest) (*domain.BulkResult, error) {
+	var v validator
+	switch request.Action {
+	case domain.BulkTag, domain.BulkUntag:
+		if request.Tags = v.tags("tags", request.Tags); len(request.Tags) == 0 {
+			v.fail("tags", "at least one tag is required")
+		}
+	case domain.BulkDelete, domain.BulkComplete, domain.BulkToggle, domain.BulkMove:
+	default:
+		v.fail("action", "unknown bulk action %q", request.Action)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	result := &domain.BulkResult{Action: request.Action, DryRun: request.DryRun}
+	err := s.repo.Atomically(ctx, func(repo domain.TodoRepository) error {
+		todos, err := selectBulk(ctx, repo, request)
+		if err != nil {
+			return err
+		}
+		result.Matched, result.Changed = len(todos), []*domain.Todo{}
+
+		now := time.Now()
+		changes, err := planBulk(request, todos, now)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			result.Changed = append(result.Changed, change.after)
+		}
+
+		if request.DryRun || len(changes) == 0 {
+			return nil
+		}
+		return s.writeBulk(ctx, repo, request, changes, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// selectBulk reads the todos request applies to. Todos picked by ID may be
+// archived; a filter only picks them when its query says so.
+func selectBulk(ctx context.Context, repo domain.TodoRepository, request domain.BulkRequest) ([]*domain.Todo, error) {
+	if len(request.IDs) == 0 {
+		return repo.FindAll(ctx, request.Query)
+	}
+
+	todos, err := repo.FindAll(ctx, domain.TodoQuery{IDs: request.IDs, IncludeArchived: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range request.IDs {
+		if !slices.ContainsFunc(todos, func(todo *domain.Todo) bool { return todo.ID == id }) {
+			return nil, fmt.Errorf("todo %s: %w", id, domain.ErrNotFound)
+		}
+	}
+	return todos, nil
+}
+
+// planBulk works out what request does to each of todos, leaving out the
+// ones it has nothing to do to.
+func planBulk(request domain.BulkRequest, todos []*domain.Todo, now time.Time) ([]*bulkChange, error) {
+	var changes []*bulkChange
+	change := func(todo *domain.Todo, event domain.EventAction) *domain.Todo {
+		after := *todo
+		changes = append(changes, &bulkChange{before: todo, after: &after, event: event})
+		return &after
+	}
+	patched := func(todo *domain.Todo, event domain.EventAction, patch domain.TodoPatch) *domain.Todo {
+		after := change(todo, event)
+		patch.UpdatedAt, patch.Version = now, todo.Version
+		patch.Apply(after)
+		return after
+	}
+
+	for _, todo := range todos {
+		switch request.Action {
+		case domain.BulkDelete:
+			after := change(todo, domain.EventDeleted)
+			after.DeletedAt = &now
+			after.Version++
+		case domain.BulkComplete, domain.BulkToggle:
+			if todo.Completed {
+				if request.Action == domain.BulkToggle {
+					after := patched(todo, domain.EventReopened, domain.TodoPatch{})
+					after.Completed, after.CompletedAt = false, nil
+				}
+				continue
+			}
+			if todo.OpenBlockers > 0 && !request.Force {
+				return nil, fmt.Errorf("%w: todo %s is blocked by %d open todo(s)", domain.ErrConflict, todo.ID, todo.OpenBlockers)
+			}
+			// As when completing a single todo, the rule moves on to the
+			// next occurrence
+			rule := ""
+			after := patched(todo, domain.EventCompleted, domain.TodoPatch{Recurrence: &rule})
+			after.Completed, after.CompletedAt = true, &now
+		case domain.BulkTag:
+			if tags := withTags(todo.Tags, request.Tags); len(tags) > len(todo.Tags) {
+				change(todo, domain.EventUpdated).Tags = tags
+			}
+		case domain.BulkUntag:
+			if tags := withoutTags(todo.Tags, request.Tags); len(tags) < len(todo.Tags) {
+				change(todo, domain.EventUpdated).Tags = tags
+			}
+		case domain.BulkMove:
+			if !equalIDs(todo.ProjectID, request.ProjectID) {
+				patched(todo, domain.EventUpdated, moveTo(request.ProjectID))
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// writeBulk writes changes with one batch call per kind of change and adds
+// their events to the history.
+func (s todoServiceImpl) writeBulk(ctx context.Context, repo domain.TodoRepository, request domain.BulkRequest, changes []*bulkChange, now time.Time) error {
+	var completing, reopening, todos []*domain.Todo
+	for _, change := range changes {
+		todos = append(todos, change.before)
+		switch change.event {
+		case domain.EventCompleted:
+			completing = append(completing, change.before)
+		case domain.EventReopened:
+			reopening = append(reopening, change.before)
+		}
+	}
+
+	var err error
+	switch request.Action {
+	case domain.BulkDelete:
+		// Ancestors are looked up before they go to the trash
+		if err = foldNested(ctx, repo, changes); err == nil {
+			err = repo.DeleteMany(ctx, todos, now)
+		}
+	case domain.BulkComplete, domain.BulkToggle:
+		rule := ""
+		if err = repo.SetCompletedMany(ctx, completing, true, domain.TodoPatch{Recurrence: &rule, UpdatedAt: now}); err == nil {
+			err = repo.SetCompletedMany(ctx, reopening, false, domain.TodoPatch{UpdatedAt: now})
+		}
+	case domain.BulkTag:
+		err = repo.AddTagsMany(ctx, todoIDs(todos), request.Tags)
+	case domain.BulkUntag:
+		err = repo.RemoveTagsMany(ctx, todoIDs(todos), request.Tags)
+	case domain.BulkMove:
+		patch := moveTo(request.ProjectID)
+		patch.UpdatedAt = now
+		err = repo.UpdateMany(ctx, todos, patch)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		switch change.event {
+		case "":
+			continue
+		case domain.EventDeleted:
+			// Recorded the way DeleteTodo records a deletion
+			event := domain.NewTodoEvent(ctx, domain.EventDeleted, nil, nil)
+			event.TodoID = change.before.ID
+			err = repo.AddEvent(ctx, event)
+		default:
+			err = repo.AddEvent(ctx, domain.NewTodoEvent(ctx, change.event, change.before, change.after))
+		}
+		if err != nil {
+			return err
+		}
+
+		if change.event == domain.EventCompleted && change.before.Recurrence != "" {
+			if change.after.NextOccurrence, err = s.spawnNextOccurrence(ctx, repo, change.before); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// foldNested leaves the deletion of a todo out of the history when one of
+// its ancestors is deleted too. It went to the trash with the ancestor, so
+// restoring the ancestor brings it back, and undo must not restore it a
+// second time.
+func foldNested(ctx context.Context, repo domain.TodoRepository, changes []*bulkChange) error {
+	selected := map[uuid.UUID]bool{}
+	for _, change := range changes {
+		selected[change.before.ID] = true
+	}
+
+	for _, change := range changes {
+		seen := map[uuid.UUID]bool{change.before.ID: true}
+		for parentID := change.before.ParentID; parentID != nil && !seen[*parentID]; {
+			if selected[*parentID] {
+				change.event = ""
+				break
+			}
+			seen[*parentID] = true
+
+			parent, err := repo.FindByID(ctx, *parentID)
+			if err != nil {
+				return fmt.Errorf("parent: %w", err)
+			}
+			parentID = parent.ParentID
+		}
+	}
+
+	return nil
+}
+
+// moveTo is the patch that moves a todo to the project, or out of its
+// project when projectID is nil.
+func moveTo(projectID *uuid.UUID) domain.TodoPatch {
+	if projectID == nil {
+		return domain.TodoPatch{ClearProject: true}
+	}
+	return domain.TodoPatch{ProjectID: projectID}
+}
+
+// withTags returns tags with add added, sorted by name the way todos come
+// back from the repository.
+func withTags(tags, add []string) []string {
+	tagged := slices.Clone(tags)
+	for _, tag := range add {
+		if !slices.Contains(tagged, tag) {
+			tagged = append(tagged, tag)
+		}
+	}
+	slices.Sort(tagged)
+	return tagged
+}
+
+// withoutTags returns tags without the ones in remove.
+func withoutTags(tags, remove []string) []string {
+	return slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+		return slices.Contains(remove, tag)
+	})
+}
+
+func equalIDs(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func todoIDs(todos []*domain.Todo) []uuid.UUID {
+	ids := make([]uuid.UUID, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+type projectServiceImpl struct {
+	repo domain.ProjectRepository
+}
+
+func NewProjectService(repo domain.ProjectRepository) domain.ProjectService {
+	return &projectServiceImpl{repo: repo}
+}
+
+func (s projectServiceImpl) FindAllProjects(ctx context.Context, includeArchived bool) ([]*domain.Project, error) {
+	return s.repo.FindAll(ctx, includeArchived)
+}
+
+func (s projectServiceImpl) FindProjectByName(ctx context.Context, name string) (*domain.Project, error) {
+	return s.repo.FindByName(ctx, strings.TrimSpace(name))
+}
+
+func (s projectServiceImpl) CreateProject(ctx context.Context, request domain.CreateProjectRequest) (*domain.Project, error) {
+	var v validator
+	name := v.text("name", request.Name, projectNameRule)
+	description := v.text("description", request.Description, projectDescriptionRule)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	project := &domain.Project{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: description,
+		Archived:    false,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (s projectServiceImpl) RenameProject(ctx context.Context, id uuid.UUID, name string) (*domain.Project, error) {
+	var v validator
+	name = v.text("name", name, projectNameRule)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return unitOfWork(ctx, s.repo, func(repo domain.ProjectRepository) (*domain.Project, error) {
+		project, err := repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		project.Name = name
+		project.UpdatedAt = time.Now()
+
+		if err = repo.Update(ctx, project); err != nil {
+			return nil, err
+		}
+
+		return project, nil
+	})
+}
+
+func (s projectServiceImpl) ArchiveProject(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	return unitOfWork(ctx, s.repo, func(repo domain.ProjectRepository) (*domain.Project, error) {
+		project, err := repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		project.Archived = true
+		project.UpdatedAt = time.Now()
+
+		if err = repo.Update(ctx, project); err != nil {
+			return nil, err
+		}
+
+		return project, nil
+	})
+}
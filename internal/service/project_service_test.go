@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (mock *MockProjectRepository) FindAll(ctx context.Context, includeArchived bool) ([]*domain.Project, error) {
+	args := mock.Called(ctx, includeArchived)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*domain.Project), args.Error(1)
+}
+
+func (mock *MockProjectRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	args := mock.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (mock *MockProjectRepository) FindByName(ctx context.Context, name string) (*domain.Project, error) {
+	args := mock.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (mock *MockProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	args := mock.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (mock *MockProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	args := mock.Called(ctx, project)
+	return args.Error(0)
+}
+
+// Atomically runs fn on the mock itself; a mock has nothing to roll back.
+func (mock *MockProjectRepository) Atomically(ctx context.Context, fn func(repo domain.ProjectRepository) error) error {
+	return fn(mock)
+}
+
+func TestProjectService_CreateProject(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Project")).Return(nil)
+
+	result, err := service.CreateProject(ctx, domain.CreateProjectRequest{Name: "  infra ", Description: "Platform work"})
+	assert.NoError(t, err)
+	assert.Equal(t, "infra", result.Name)
+	assert.Equal(t, "Platform work", result.Description)
+	assert.False(t, result.Archived)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProjectService_CreateProject_EmptyName(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+
+	_, err := service.CreateProject(context.Background(), domain.CreateProjectRequest{Name: " "})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestProjectService_RenameProject(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Project{ID: testID, Name: "infra"}, nil)
+	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Project")).Return(nil)
+
+	result, err := service.RenameProject(ctx, testID, "platform")
+	assert.NoError(t, err)
+	assert.Equal(t, "platform", result.Name)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProjectService_ArchiveProject(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewProjectService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Project{ID: testID, Name: "infra"}, nil)
+	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Project")).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			project := args.Get(1).(*domain.Project)
+			assert.True(t, project.Archived)
+		})
+
+	result, err := service.ArchiveProject(ctx, testID)
+	assert.NoError(t, err)
+	assert.True(t, result.Archived)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/recurrence"
 )
 
 type todoServiceImpl struct {
@@ -16,63 +21,1065 @@ func NewTodoService(repo domain.TodoRepository) domain.TodoService {
 	return &todoServiceImpl{repo: repo}
 }
 
-func (s todoServiceImpl) FindAllTodos(ctx context.Context) ([]*domain.Todo, error) {
-	return s.repo.FindAll(ctx)
+func (s todoServiceImpl) FindAllTodos(ctx context.Context, query domain.TodoQuery) ([]*domain.Todo, error) {
+	var v validator
+	if query.Limit < 0 {
+		v.fail("limit", "cannot be negative")
+	}
+	if query.Offset < 0 {
+		v.fail("offset", "cannot be negative")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindAll(ctx, query)
 }
 
 func (s todoServiceImpl) FindTodoByID(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
+func (s todoServiceImpl) ResolveTodoID(ctx context.Context, ref string) (uuid.UUID, error) {
+	return s.repo.ResolveID(ctx, ref, domain.TodoQuery{IncludeArchived: true})
+}
+
+func (s todoServiceImpl) ResolveTrashedTodoID(ctx context.Context, ref string) (uuid.UUID, error) {
+	return s.repo.ResolveID(ctx, ref, domain.TodoQuery{Trashed: true})
+}
+
 func (s todoServiceImpl) CreateTodo(ctx context.Context, request domain.CreateTodoRequest) (*domain.Todo, error) {
-	todo := &domain.Todo{
-		ID:          uuid.New(),
-		Title:       request.Title,
-		Description: request.Description,
-		Completed:   false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	var v validator
+	title := v.text("title", request.Title, titleRule)
+	description := v.text("description", request.Description, descriptionRule)
+	v.priority("priority", request.Priority)
+	tags := v.tags("tags", request.Tags)
+	rule := v.recurrence("recurrence", request.Recurrence)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(ctx, todo); err != nil {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		id := uuid.New()
+		if request.ParentID != nil {
+			if err := s.checkParent(ctx, id, *request.ParentID); err != nil {
+				return nil, err
+			}
+		}
+
+		todo := &domain.Todo{
+			ID:          id,
+			Title:       title,
+			Description: description,
+			Completed:   false,
+			Priority:    request.Priority,
+			DueDate:     request.DueDate,
+			Tags:        tags,
+			ProjectID:   request.ProjectID,
+			ParentID:    request.ParentID,
+			Recurrence:  rule,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Version:     1,
+		}
+
+		err := s.record(ctx, domain.NewTodoEvent(ctx, domain.EventCreated, nil, todo), func(repo domain.TodoRepository) error {
+			return repo.Create(ctx, todo)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return todo, nil
+	})
+}
+
+// UpdateTodo writes only the fields the request sets, and nothing when
+// they already have the values it sets them to.
+func (s todoServiceImpl) UpdateTodo(ctx context.Context, request domain.UpdateTodoRequest) (*domain.Todo, error) {
+	patch := domain.TodoPatch{
+		Priority:     request.Priority,
+		DueDate:      request.DueDate,
+		ClearDueDate: request.ClearDueDate,
+		ProjectID:    request.ProjectID,
+		ClearProject: request.ClearProject,
+		ParentID:     request.ParentID,
+		ClearParent:  request.ClearParent,
+		UpdatedAt:    time.Now(),
+	}
+
+	var v validator
+	if request.Title != nil {
+		title := v.text("title", *request.Title, titleRule)
+		patch.Title = &title
+	}
+	if request.ClearDescription {
+		description := ""
+		patch.Description = &description
+	} else if request.Description != nil {
+		description := v.text("description", *request.Description, descriptionRule)
+		patch.Description = &description
+	}
+	if request.Priority != nil {
+		v.priority("priority", *request.Priority)
+	}
+	if request.ClearRecurrence {
+		rule := ""
+		patch.Recurrence = &rule
+	} else if request.Recurrence != nil {
+		rule := v.recurrence("recurrence", *request.Recurrence)
+		patch.Recurrence = &rule
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		// A unit of work may run more than once, so each run starts from the
+		// patch the request asked for
+		patch := patch
+		todo, err := s.repo.FindByID(ctx, request.ID)
+		if err != nil {
+			return nil, err
+		}
+		patch.Version = todo.Version
+		if request.Version != 0 {
+			patch.Version = request.Version
+		}
+
+		if request.AppendDescription != nil && !request.ClearDescription {
+			description := todo.Description
+			if patch.Description != nil {
+				description = *patch.Description
+			}
+			if extra := strings.TrimSpace(*request.AppendDescription); extra != "" {
+				if description != "" {
+					description += "\n"
+				}
+				description = v.text("description", description+extra, descriptionRule)
+				patch.Description = &description
+			}
+			if err := v.err(); err != nil {
+				return nil, err
+			}
+		}
+
+		if request.ParentID != nil && !request.ClearParent {
+			if err := s.checkParent(ctx, todo.ID, *request.ParentID); err != nil {
+				return nil, err
+			}
+		}
+
+		before := *todo
+		patch.Apply(todo)
+
+		// An update that changes nothing writes nothing, so it neither bumps
+		// the version nor leaves an empty event for undo to pick up. One
+		// based on an older version still fails as stale.
+		event := domain.NewTodoEvent(ctx, domain.EventUpdated, &before, todo)
+		if len(event.Changes) == 0 && patch.Version == before.Version {
+			return &before, nil
+		}
+
+		err = s.record(ctx, event, func(repo domain.TodoRepository) error {
+			return repo.Update(ctx, todo.ID, patch)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return todo, nil
+	})
+}
+
+func (s todoServiceImpl) DeleteTodo(ctx context.Context, id uuid.UUID) error {
+	event := domain.NewTodoEvent(ctx, domain.EventDeleted, nil, nil)
+	event.TodoID = id
+	return s.record(ctx, event, func(repo domain.TodoRepository) error {
+		return repo.Delete(ctx, id, event.OccurredAt)
+	})
+}
+
+func (s todoServiceImpl) RestoreTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		trashed, err := restorable(ctx, s.repo, id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.record(ctx, domain.NewTodoEvent(ctx, domain.EventRestored, trashed, trashed), func(repo domain.TodoRepository) error {
+			return repo.Restore(ctx, id)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+// restorable finds todo id in the trash, failing with ErrConflict while its
+// parent is in there too: a restored subtask would otherwise hang under a
+// parent nobody can see.
+func restorable(ctx context.Context, repo domain.TodoRepository, id uuid.UUID) (*domain.Todo, error) {
+	trashed, err := repo.FindAll(ctx, domain.TodoQuery{Trashed: true, IDs: []uuid.UUID{id}})
+	if err != nil {
 		return nil, err
 	}
+	if len(trashed) == 0 {
+		return nil, fmt.Errorf("todo %s in the trash: %w", id, domain.ErrNotFound)
+	}
 
-	return todo, nil
+	if parentID := trashed[0].ParentID; parentID != nil {
+		if _, err := repo.FindByID(ctx, *parentID); errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: the parent of todo %s is in the trash, restore %s first", domain.ErrConflict, id, parentID)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return trashed[0], nil
 }
 
-func (s todoServiceImpl) UpdateTodo(ctx context.Context, request domain.UpdateTodoRequest) (*domain.Todo, error) {
-	todo, err := s.repo.FindByID(ctx, request.ID)
+func (s todoServiceImpl) EmptyTrash(ctx context.Context, before time.Time) (int, error) {
+	return s.repo.Purge(ctx, before)
+}
+
+func (s todoServiceImpl) ArchiveTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if todo.ArchivedAt != nil {
+			return todo, nil
+		}
+
+		archived := *todo
+		now := time.Now()
+		archived.ArchivedAt = &now
+
+		err = s.record(ctx, domain.NewTodoEvent(ctx, domain.EventUpdated, todo, &archived), func(repo domain.TodoRepository) error {
+			_, err := repo.Archive(ctx, domain.TodoQuery{IDs: []uuid.UUID{id}}, now)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+func (s todoServiceImpl) UnarchiveTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if todo.ArchivedAt == nil {
+			return todo, nil
+		}
+
+		unarchived := *todo
+		unarchived.ArchivedAt = nil
+
+		err = s.record(ctx, domain.NewTodoEvent(ctx, domain.EventUpdated, todo, &unarchived), func(repo domain.TodoRepository) error {
+			return repo.Unarchive(ctx, id)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+// ArchiveCompleted records the archiving of each todo it archives, as
+// ArchiveTodo does.
+func (s todoServiceImpl) ArchiveCompleted(ctx context.Context, before time.Time) (int, error) {
+	completed := true
+	query := domain.TodoQuery{Completed: &completed, CompletedBefore: &before}
+
+	return unitOfWork(ctx, s.repo, func(repo domain.TodoRepository) (int, error) {
+		todos, err := repo.FindAll(ctx, query)
+		if err != nil {
+			return 0, err
+		}
+
+		now := time.Now()
+		for _, todo := range todos {
+			archived := *todo
+			archived.ArchivedAt = &now
+			if err := repo.AddEvent(ctx, domain.NewTodoEvent(ctx, domain.EventUpdated, todo, &archived)); err != nil {
+				return 0, err
+			}
+		}
+
+		return repo.Archive(ctx, query, now)
+	})
+}
+
+func (s todoServiceImpl) ToggleTodo(ctx context.Context, id uuid.UUID, opts domain.ToggleOptions) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if todo.Completed {
+			err = s.reopen(ctx, todo)
+		} else {
+			err = s.complete(ctx, todo, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return todo, nil
+	})
+}
+
+func (s todoServiceImpl) CompleteTodo(ctx context.Context, id uuid.UUID, opts domain.ToggleOptions) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = s.complete(ctx, todo, opts); err != nil {
+			return nil, err
+		}
+
+		return todo, nil
+	})
+}
+
+func (s todoServiceImpl) ReopenTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = s.reopen(ctx, todo); err != nil {
+			return nil, err
+		}
+
+		return todo, nil
+	})
+}
+
+// complete marks todo done and spawns its next occurrence. With
+// opts.Cascade its pending subtasks are completed too, even when the todo
+// itself already was done. It runs in the unit of work its caller opened.
+func (s todoServiceImpl) complete(ctx context.Context, todo *domain.Todo, opts domain.ToggleOptions) error {
+	if !todo.Completed {
+		if todo.OpenBlockers > 0 && !opts.Force {
+			return fmt.Errorf("%w: todo %s is blocked by %d open todo(s)", domain.ErrConflict, todo.ID, todo.OpenBlockers)
+		}
+		if err := s.markDone(ctx, todo); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Cascade {
+		return nil
+	}
+
+	subtasks, err := s.FindSubtasks(ctx, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	spawned := 0
+	for _, subtask := range subtasks {
+		if subtask.Completed {
+			continue
+		}
+		if subtask.OpenBlockers > 0 && !opts.Force {
+			return fmt.Errorf("%w: subtask %s is blocked by %d open todo(s)", domain.ErrConflict, subtask.ID, subtask.OpenBlockers)
+		}
+
+		// A stale subtask rolls back the todo too, since callers complete
+		// it in the same unit of work, so the toggle can simply be retried
+		if err := s.markDone(ctx, subtask); err != nil {
+			return err
+		}
+		if subtask.NextOccurrence != nil && *subtask.ParentID == todo.ID {
+			spawned++
+		}
+	}
+	// The next occurrence of a direct subtask is a new pending one
+	todo.Subtasks.Done = todo.Subtasks.Total
+	todo.Subtasks.Total += spawned
+
+	return nil
+}
+
+// markDone writes todo as completed, spawning its next occurrence if it
+// recurs, and updates todo to match.
+func (s todoServiceImpl) markDone(ctx context.Context, todo *domain.Todo) error {
+	patch := domain.TodoPatch{UpdatedAt: time.Now(), Version: todo.Version}
+	spawn := todo.Recurrence != ""
+	if spawn {
+		// The rule moves to the next occurrence, so completing this todo
+		// again does not spawn a second copy
+		rule := ""
+		patch.Recurrence = &rule
+	}
+
+	done := *todo
+	patch.Apply(&done)
+	done.Completed = true
+	done.CompletedAt = &patch.UpdatedAt
+
+	// Write the todo before spawning, so a stale toggle leaves nothing
+	// behind and can simply be retried
+	err := s.record(ctx, domain.NewTodoEvent(ctx, domain.EventCompleted, todo, &done), func(repo domain.TodoRepository) error {
+		if err := repo.SetCompleted(ctx, todo.ID, true, patch); err != nil {
+			return err
+		}
+		if spawn {
+			next, err := s.spawnNextOccurrence(ctx, repo, todo)
+			if err != nil {
+				return err
+			}
+			done.NextOccurrence = next
+		}
+		return nil
+	})
 	if err != nil {
+		return err
+	}
+
+	*todo = done
+	return nil
+}
+
+// reopen marks todo pending again; nothing is written if it already is.
+func (s todoServiceImpl) reopen(ctx context.Context, todo *domain.Todo) error {
+	if !todo.Completed {
+		return nil
+	}
+
+	patch := domain.TodoPatch{UpdatedAt: time.Now(), Version: todo.Version}
+	pending := *todo
+	patch.Apply(&pending)
+	pending.Completed = false
+	pending.CompletedAt = nil
+
+	err := s.record(ctx, domain.NewTodoEvent(ctx, domain.EventReopened, todo, &pending), func(repo domain.TodoRepository) error {
+		return repo.SetCompleted(ctx, todo.ID, false, patch)
+	})
+	if err != nil {
+		return err
+	}
+
+	*todo = pending
+	return nil
+}
+
+// FindSubtasks returns every descendant of the todo, breadth first, oldest
+// first within each level.
+func (s todoServiceImpl) FindSubtasks(ctx context.Context, id uuid.UUID) ([]*domain.Todo, error) {
+	var subtasks []*domain.Todo
+
+	queue := []uuid.UUID{id}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+
+		children, err := s.repo.FindAll(ctx, domain.TodoQuery{
+			ParentID:        &parentID,
+			IncludeArchived: true,
+			Sort:            []domain.SortKey{{Field: domain.SortCreated, Reverse: true}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			subtasks = append(subtasks, child)
+			queue = append(queue, child.ID)
+		}
+	}
+
+	return subtasks, nil
+}
+
+func (s todoServiceImpl) AddTags(ctx context.Context, id uuid.UUID, tags []string) (*domain.Todo, error) {
+	var v validator
+	tags = v.tags("tags", tags)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
 
-	todo.Title = request.Title
-	todo.Description = request.Description
-	todo.UpdatedAt = time.Now()
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		tagged := *todo
+		if tagged.Tags = withTags(todo.Tags, tags); len(tagged.Tags) == len(todo.Tags) {
+			return todo, nil
+		}
 
-	if err = s.repo.Update(ctx, todo); err != nil {
+		err = s.record(ctx, domain.NewTodoEvent(ctx, domain.EventUpdated, todo, &tagged), func(repo domain.TodoRepository) error {
+			return repo.AddTags(ctx, id, tags)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+func (s todoServiceImpl) RemoveTags(ctx context.Context, id uuid.UUID, tags []string) (*domain.Todo, error) {
+	var v validator
+	tags = v.tags("tags", tags)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
 
-	return todo, nil
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		tagged := *todo
+		if tagged.Tags = withoutTags(todo.Tags, tags); len(tagged.Tags) == len(todo.Tags) {
+			return todo, nil
+		}
+
+		err = s.record(ctx, domain.NewTodoEvent(ctx, domain.EventUpdated, todo, &tagged), func(repo domain.TodoRepository) error {
+			return repo.RemoveTags(ctx, id, tags)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
 }
 
-func (s todoServiceImpl) DeleteTodo(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+func (s todoServiceImpl) FindAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	return s.repo.FindAllTags(ctx)
 }
 
-func (s todoServiceImpl) ToggleTodo(ctx context.Context, id uuid.UUID) (*domain.Todo, error) {
-	todo, err := s.repo.FindByID(ctx, id)
+// BlockTodo records that the todo cannot be completed before blockerID is.
+// Dependencies that would form a cycle are rejected.
+func (s todoServiceImpl) BlockTodo(ctx context.Context, id, blockerID uuid.UUID) (*domain.Todo, error) {
+	if id == blockerID {
+		return nil, fmt.Errorf("%w: todo %s cannot block itself", domain.ErrValidation, id)
+	}
+
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.FindByID(ctx, blockerID); err != nil {
+			return nil, fmt.Errorf("blocker: %w", err)
+		}
+
+		if err := s.checkDependencyCycle(ctx, id, blockerID); err != nil {
+			return nil, err
+		}
+
+		blockers, err := s.blockerIDs(ctx, id)
+		if err != nil || slices.Contains(blockers, blockerID) {
+			return todo, err
+		}
+
+		err = s.recordBlockers(ctx, todo, blockers, append(slices.Clone(blockers), blockerID), func(repo domain.TodoRepository) error {
+			return repo.AddBlocker(ctx, id, blockerID)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+func (s todoServiceImpl) UnblockTodo(ctx context.Context, id, blockerID uuid.UUID) (*domain.Todo, error) {
+	return atomically(ctx, s, func(s todoServiceImpl) (*domain.Todo, error) {
+		todo, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		blockers, err := s.blockerIDs(ctx, id)
+		if err != nil || !slices.Contains(blockers, blockerID) {
+			return todo, err
+		}
+
+		remaining := slices.DeleteFunc(slices.Clone(blockers), func(other uuid.UUID) bool { return other == blockerID })
+		err = s.recordBlockers(ctx, todo, blockers, remaining, func(repo domain.TodoRepository) error {
+			return repo.RemoveBlocker(ctx, id, blockerID)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return s.repo.FindByID(ctx, id)
+	})
+}
+
+func (s todoServiceImpl) FindHistory(ctx context.Context, id uuid.UUID) ([]*domain.TodoEvent, error) {
+	return s.repo.FindEvents(ctx, id)
+}
+
+func (s todoServiceImpl) FindUndoable(ctx context.Context, n int) ([]*domain.Operation, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: the number of operations must be at least 1", domain.ErrValidation)
+	}
+	return s.repo.FindUndoable(ctx, domain.ActorFrom(ctx), n)
+}
+
+func (s todoServiceImpl) FindRedoable(ctx context.Context, n int) ([]*domain.Operation, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: the number of operations must be at least 1", domain.ErrValidation)
+	}
+	return s.repo.FindRedoable(ctx, domain.ActorFrom(ctx), n)
+}
+
+// Undo reverts the events of each operation newest first. Undoing writes
+// no events of its own; the operations are marked undone instead, so Redo
+// can find them again.
+func (s todoServiceImpl) Undo(ctx context.Context, operations []*domain.Operation) error {
+	return s.repo.Atomically(ctx, func(repo domain.TodoRepository) error {
+		now := time.Now()
+		for _, operation := range operations {
+			for i := len(operation.Events) - 1; i >= 0; i-- {
+				if err := s.revert(ctx, repo, operation.Events[i], true); err != nil {
+					return err
+				}
+			}
+			if err := repo.SetUndone(ctx, operation.ID, &now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s todoServiceImpl) Redo(ctx context.Context, operations []*domain.Operation) error {
+	return s.repo.Atomically(ctx, func(repo domain.TodoRepository) error {
+		for _, operation := range operations {
+			for _, event := range operation.Events {
+				if err := s.revert(ctx, repo, event, false); err != nil {
+					return err
+				}
+			}
+			if err := repo.SetUndone(ctx, operation.ID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// revert takes the todo of event back to before it, or with undo false
+// forward to after it again.
+func (s todoServiceImpl) revert(ctx context.Context, repo domain.TodoRepository, event *domain.TodoEvent, undo bool) error {
+	switch event.Action {
+	case domain.EventCreated, domain.EventRestored, domain.EventDeleted:
+		// Undoing a deletion brings the todo back, undoing the others
+		// takes it away
+		if (event.Action == domain.EventDeleted) != undo {
+			return repo.Delete(ctx, event.TodoID, time.Now())
+		}
+		_, err := restorable(ctx, repo, event.TodoID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: todo %s is no longer in the trash", domain.ErrConflict, event.TodoID)
+		} else if err != nil {
+			return err
+		}
+		return repo.Restore(ctx, event.TodoID)
+	}
+
+	todo, err := repo.FindByID(ctx, event.TodoID)
+	if err != nil {
+		return err
+	}
+
+	patch := domain.TodoPatch{UpdatedAt: time.Now(), Version: todo.Version}
+	completed, patched := todo.Completed, false
+	// related are the changes written outside the patch, from what the todo
+	// has now to what it gets
+	var related []domain.FieldChange
+	for _, change := range event.Changes {
+		from, to := change.New, change.Old
+		if !undo {
+			from, to = to, from
+		}
+		current, ok, err := fieldValue(ctx, repo, todo, change.Field)
+		if err != nil {
+			return err
+		}
+		if !ok || !equalValues(sortedValue(current), sortedValue(from)) {
+			return fmt.Errorf("%w: the %s of todo %s changed since, undo it by hand", domain.ErrConflict, change.Field, todo.ID)
+		}
+
+		switch change.Field {
+		case "tags", "blocked_by", "archived":
+			related = append(related, domain.FieldChange{Field: change.Field, Old: current, New: to})
+		default:
+			patched = true
+			if err := setField(&patch, &completed, change.Field, to); err != nil {
+				return err
+			}
+		}
+	}
+
+	if completed != todo.Completed {
+		err = repo.SetCompleted(ctx, todo.ID, completed, patch)
+	} else if patched {
+		err = repo.Update(ctx, todo.ID, patch)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, change := range related {
+		if err := setRelated(ctx, repo, todo.ID, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldValue is domain.FieldValue that also knows the blockers of todo,
+// which are recorded as the field "blocked_by".
+func fieldValue(ctx context.Context, repo domain.TodoRepository, todo *domain.Todo, field string) (*string, bool, error) {
+	if field != "blocked_by" {
+		value, ok := domain.FieldValue(todo, field)
+		return value, ok, nil
+	}
+
+	blockers, err := repo.FindAll(ctx, domain.TodoQuery{Blocking: &todo.ID, IncludeArchived: true})
+	if err != nil {
+		return nil, false, err
+	}
+	return domain.EventIDs(todoIDs(blockers)), true, nil
+}
+
+// setRelated writes change to a field the todo patch does not cover, from
+// change.Old, which the todo has now, to change.New.
+func setRelated(ctx context.Context, repo domain.TodoRepository, id uuid.UUID, change domain.FieldChange) error {
+	from, to := splitValue(change.Old), splitValue(change.New)
+	removed := slices.DeleteFunc(slices.Clone(from), func(v string) bool { return slices.Contains(to, v) })
+	added := slices.DeleteFunc(slices.Clone(to), func(v string) bool { return slices.Contains(from, v) })
+
+	switch change.Field {
+	case "archived":
+		if change.New == nil {
+			return repo.Unarchive(ctx, id)
+		}
+		_, err := repo.Archive(ctx, domain.TodoQuery{IDs: []uuid.UUID{id}}, time.Now())
+		return err
+	case "tags":
+		if len(removed) > 0 {
+			if err := repo.RemoveTags(ctx, id, removed); err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			return repo.AddTags(ctx, id, added)
+		}
+	case "blocked_by":
+		for i, values := range [][]string{removed, added} {
+			for _, value := range values {
+				blockerID, err := uuid.Parse(value)
+				if err != nil {
+					return err
+				}
+				if i == 0 {
+					err = repo.RemoveBlocker(ctx, id, blockerID)
+				} else {
+					err = repo.AddBlocker(ctx, id, blockerID)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// splitValue splits a recorded list, such as tags, into its items.
+func splitValue(value *string) []string {
+	if value == nil {
+		return nil
+	}
+	return strings.Split(*value, ",")
+}
+
+// sortedValue sorts the items of a recorded list, so lists with the same
+// items compare equal whatever order the database returned them in.
+func sortedValue(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	items := splitValue(value)
+	slices.Sort(items)
+	sorted := strings.Join(items, ",")
+	return &sorted
+}
+
+// setField sets field to value, in the form TodoChanges writes it, on patch
+// or, for the completed field, on completed.
+func setField(patch *domain.TodoPatch, completed *bool, field string, value *string) error {
+	var v string
+	if value != nil {
+		v = *value
+	}
+
+	switch field {
+	case "title":
+		patch.Title = &v
+	case "description":
+		patch.Description = &v
+	case "recurrence":
+		patch.Recurrence = &v
+	case "completed":
+		*completed = value != nil
+	case "priority":
+		priority := domain.PriorityNone
+		if value != nil {
+			p, err := domain.ParsePriority(v)
+			if err != nil {
+				return err
+			}
+			priority = p
+		}
+		patch.Priority = &priority
+	case "due_date":
+		if value == nil {
+			patch.ClearDueDate = true
+			break
+		}
+		due, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		patch.DueDate = &due
+	case "project_id", "parent_id":
+		var id *uuid.UUID
+		if value != nil {
+			parsed, err := uuid.Parse(v)
+			if err != nil {
+				return err
+			}
+			id = &parsed
+		}
+		if field == "project_id" {
+			patch.ProjectID, patch.ClearProject = id, id == nil
+		} else {
+			patch.ParentID, patch.ClearParent = id, id == nil
+		}
+	}
+	return nil
+}
+
+func equalValues(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// blockerIDs returns the IDs of the todos FindBlockers returns.
+func (s todoServiceImpl) blockerIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	blockers, err := s.FindBlockers(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	return todoIDs(blockers), nil
+}
 
-	todo.Completed = !todo.Completed
+// recordBlockers writes a change to the blockers of todo and records it.
+// The blockers are not a field of the todo, so the event carries them
+// itself.
+func (s todoServiceImpl) recordBlockers(ctx context.Context, todo *domain.Todo, before, after []uuid.UUID, write func(repo domain.TodoRepository) error) error {
+	event := domain.NewTodoEvent(ctx, domain.EventUpdated, todo, todo)
+	event.Changes = []domain.FieldChange{domain.BlockersChange(before, after)}
+	return s.record(ctx, event, write)
+}
 
-	if err = s.repo.Update(ctx, todo); err != nil {
+// FindBlockers returns the todos the todo is directly blocked by, open or not.
+func (s todoServiceImpl) FindBlockers(ctx context.Context, id uuid.UUID) ([]*domain.Todo, error) {
+	return s.repo.FindAll(ctx, domain.TodoQuery{Blocking: &id, IncludeArchived: true})
+}
+
+func (s todoServiceImpl) CompletionStats(ctx context.Context, since time.Time) (*domain.CompletionStats, error) {
+	now := time.Now()
+	if !since.Before(now) {
+		return nil, fmt.Errorf("%w: since must be in the past", domain.ErrValidation)
+	}
+
+	completed := true
+	// Archiving a todo does not take back its completion
+	todos, err := s.repo.FindAll(ctx, domain.TodoQuery{Completed: &completed, CompletedAfter: &since, IncludeArchived: true})
+	if err != nil {
 		return nil, err
 	}
 
-	return todo, nil
+	stats := &domain.CompletionStats{Since: since, Completed: len(todos)}
+
+	days := map[time.Time]int{}
+	weeks := map[time.Time]int{}
+	leadTimes := make([]time.Duration, 0, len(todos))
+	for _, todo := range todos {
+		day := startOfDay(*todo.CompletedAt)
+		days[day]++
+		weeks[startOfWeek(day)]++
+		leadTimes = append(leadTimes, max(todo.CompletedAt.Sub(todo.CreatedAt), 0))
+	}
+
+	for day := startOfDay(since); !day.After(now); day = day.AddDate(0, 0, 1) {
+		stats.Days = append(stats.Days, domain.PeriodCount{Start: day, Count: days[day]})
+	}
+	for week := startOfWeek(startOfDay(since)); !week.After(now); week = week.AddDate(0, 0, 7) {
+		stats.Weeks = append(stats.Weeks, domain.PeriodCount{Start: week, Count: weeks[week]})
+	}
+	stats.MedianLeadTime = domain.Duration(median(leadTimes))
+
+	return stats, nil
+}
+
+// startOfDay returns local midnight of t's day.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Local().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek returns the Monday of day's week; day has to be a midnight.
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// median returns the middle of durations, or the mean of the two middle ones
+// for an even count; it sorts durations in place.
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	slices.Sort(durations)
+	middle := len(durations) / 2
+	if len(durations)%2 == 1 {
+		return durations[middle]
+	}
+	return (durations[middle-1] + durations[middle]) / 2
+}
+
+// checkDependencyCycle walks everything blockerID is transitively blocked by
+// and fails if it reaches id, since id would then end up waiting on itself.
+func (s todoServiceImpl) checkDependencyCycle(ctx context.Context, id, blockerID uuid.UUID) error {
+	seen := map[uuid.UUID]bool{blockerID: true}
+	queue := []uuid.UUID{blockerID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		blockers, err := s.repo.FindAll(ctx, domain.TodoQuery{Blocking: &current, IncludeArchived: true})
+		if err != nil {
+			return err
+		}
+
+		for _, blocker := range blockers {
+			if blocker.ID == id {
+				return fmt.Errorf("%w: todo %s already waits on %s, blocking it would create a cycle", domain.ErrConflict, blockerID, id)
+			}
+			if !seen[blocker.ID] {
+				seen[blocker.ID] = true
+				queue = append(queue, blocker.ID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// spawnNextOccurrence creates the next occurrence of a recurring todo, due one
+// period after the todo's due date, or after now when it has none. Occurrences
+// that have already passed are skipped.
+func (s todoServiceImpl) spawnNextOccurrence(ctx context.Context, repo domain.TodoRepository, todo *domain.Todo) (*domain.Todo, error) {
+	rule, err := recurrence.Parse(todo.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	due := now
+	if todo.DueDate != nil {
+		due = *todo.DueDate
+	}
+	due = rule.Next(due)
+	for !due.After(now) {
+		due = rule.Next(due)
+	}
+
+	next := &domain.Todo{
+		ID:          uuid.New(),
+		Title:       todo.Title,
+		Description: todo.Description,
+		Priority:    todo.Priority,
+		DueDate:     &due,
+		Tags:        todo.Tags,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Recurrence:  todo.Recurrence,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Version:     1,
+	}
+	if err := repo.Create(ctx, next); err != nil {
+		return nil, err
+	}
+	if err := repo.AddEvent(ctx, domain.NewTodoEvent(ctx, domain.EventCreated, nil, next)); err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// record runs write and adds event to the history in the same transaction,
+// so the history never shows a change that did not happen.
+func (s todoServiceImpl) record(ctx context.Context, event *domain.TodoEvent, write func(repo domain.TodoRepository) error) error {
+	return s.repo.Atomically(ctx, func(repo domain.TodoRepository) error {
+		if err := write(repo); err != nil {
+			return err
+		}
+		return repo.AddEvent(ctx, event)
+	})
+}
+
+// checkParent makes sure parentID exists and that hanging the todo under it
+// would not create a cycle, i.e. that the todo is not one of its ancestors.
+func (s todoServiceImpl) checkParent(ctx context.Context, id, parentID uuid.UUID) error {
+	seen := map[uuid.UUID]bool{}
+	for ancestorID := &parentID; ancestorID != nil; {
+		if *ancestorID == id {
+			return fmt.Errorf("%w: todo %s cannot be a subtask of itself or of its own subtasks", domain.ErrConflict, id)
+		}
+		if seen[*ancestorID] {
+			break
+		}
+		seen[*ancestorID] = true
+
+		ancestor, err := s.repo.FindByID(ctx, *ancestorID)
+		if err != nil {
+			return fmt.Errorf("parent: %w", err)
+		}
+		ancestorID = ancestor.ParentID
+	}
+
+	return nil
 }
@@ -2,21 +2,28 @@ package service
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockTodoRepository struct {
 	mock.Mock
+	// events collects what AddEvent is given, instead of expecting each call
+	events []*domain.TodoEvent
+	// atomic is set while Atomically runs
+	atomic bool
 }
 
-func (mock *MockTodoRepository) FindAll(ctx context.Context) ([]*domain.Todo, error) {
-	args := mock.Called(ctx)
+func (mock *MockTodoRepository) FindAll(ctx context.Context, query domain.TodoQuery) ([]*domain.Todo, error) {
+	args := mock.Called(ctx, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -33,18 +40,147 @@ func (mock *MockTodoRepository) FindByID(ctx context.Context, id uuid.UUID) (*do
 	return args.Get(0).(*domain.Todo), args.Error(1)
 }
 
+func (mock *MockTodoRepository) ResolveID(ctx context.Context, ref string, scope domain.TodoQuery) (uuid.UUID, error) {
+	args := mock.Called(ctx, ref, scope)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func (mock *MockTodoRepository) Create(ctx context.Context, todo *domain.Todo) error {
 	args := mock.Called(ctx, todo)
 	return args.Error(0)
 }
 
-func (mock *MockTodoRepository) Update(ctx context.Context, todo *domain.Todo) error {
-	args := mock.Called(ctx, todo)
+func (mock *MockTodoRepository) Update(ctx context.Context, id uuid.UUID, patch domain.TodoPatch) error {
+	args := mock.Called(ctx, id, patch)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) SetCompleted(ctx context.Context, id uuid.UUID, completed bool, patch domain.TodoPatch) error {
+	args := mock.Called(ctx, id, completed, patch)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) UpdateMany(ctx context.Context, todos []*domain.Todo, patch domain.TodoPatch) error {
+	args := mock.Called(ctx, todos, patch)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) SetCompletedMany(ctx context.Context, todos []*domain.Todo, completed bool, patch domain.TodoPatch) error {
+	args := mock.Called(ctx, todos, completed, patch)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) Delete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	args := mock.Called(ctx, id, deletedAt)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) DeleteMany(ctx context.Context, todos []*domain.Todo, deletedAt time.Time) error {
+	args := mock.Called(ctx, todos, deletedAt)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) Archive(ctx context.Context, query domain.TodoQuery, archivedAt time.Time) (int, error) {
+	args := mock.Called(ctx, query, archivedAt)
+	return args.Int(0), args.Error(1)
+}
+
+func (mock *MockTodoRepository) Unarchive(ctx context.Context, id uuid.UUID) error {
+	args := mock.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (mock *MockTodoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (mock *MockTodoRepository) AddEvent(ctx context.Context, event *domain.TodoEvent) error {
+	mock.events = append(mock.events, event)
+	return nil
+}
+
+func (mock *MockTodoRepository) FindEvents(ctx context.Context, id uuid.UUID) ([]*domain.TodoEvent, error) {
 	args := mock.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*domain.TodoEvent), args.Error(1)
+}
+
+func (mock *MockTodoRepository) FindUndoable(ctx context.Context, actor string, limit int) ([]*domain.Operation, error) {
+	args := mock.Called(ctx, actor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*domain.Operation), args.Error(1)
+}
+
+func (mock *MockTodoRepository) FindRedoable(ctx context.Context, actor string, limit int) ([]*domain.Operation, error) {
+	args := mock.Called(ctx, actor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*domain.Operation), args.Error(1)
+}
+
+func (mock *MockTodoRepository) SetUndone(ctx context.Context, operation uuid.UUID, undoneAt *time.Time) error {
+	args := mock.Called(ctx, operation, undoneAt)
+	return args.Error(0)
+}
+
+// Atomically runs fn on the mock itself; a mock has nothing to roll back.
+func (mock *MockTodoRepository) Atomically(ctx context.Context, fn func(repo domain.TodoRepository) error) error {
+	outer := mock.atomic
+	mock.atomic = true
+	defer func() { mock.atomic = outer }()
+	return fn(mock)
+}
+
+func (mock *MockTodoRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	args := mock.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (mock *MockTodoRepository) AddTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	args := mock.Called(ctx, id, tags)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) AddTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error {
+	args := mock.Called(ctx, ids, tags)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) RemoveTagsMany(ctx context.Context, ids []uuid.UUID, tags []string) error {
+	args := mock.Called(ctx, ids, tags)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) RemoveTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	args := mock.Called(ctx, id, tags)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) FindAllTags(ctx context.Context) ([]*domain.TagCount, error) {
+	args := mock.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*domain.TagCount), args.Error(1)
+}
+
+func (mock *MockTodoRepository) AddBlocker(ctx context.Context, id, blockerID uuid.UUID) error {
+	args := mock.Called(ctx, id, blockerID)
+	return args.Error(0)
+}
+
+func (mock *MockTodoRepository) RemoveBlocker(ctx context.Context, id, blockerID uuid.UUID) error {
+	args := mock.Called(ctx, id, blockerID)
 	return args.Error(0)
 }
 
@@ -66,9 +202,10 @@ func TestTodoService_FindAllTodos(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindAll").Return(expectedTodos, nil)
+	query := domain.TodoQuery{Sort: []domain.SortKey{{Field: domain.SortPriority}}}
+	mockRepo.On("FindAll", ctx, query).Return(expectedTodos, nil)
 
-	result, err := service.FindAllTodos(ctx)
+	result, err := service.FindAllTodos(ctx, query)
 	assert.NoError(t, err)
 	assert.Len(t, result, len(expectedTodos))
 
@@ -102,10 +239,10 @@ func TestTodoService_FindTodoByID_NotFound(t *testing.T) {
 	ctx := context.Background()
 
 	testID := uuid.New()
-	mockRepo.On("FindByID", ctx, testID).Return(nil, errors.New("not found"))
+	mockRepo.On("FindByID", ctx, testID).Return(nil, domain.ErrNotFound)
 
 	todo, err := service.FindTodoByID(ctx, testID)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
 	assert.Nil(t, todo)
 
 	mockRepo.AssertExpectations(t)
@@ -116,9 +253,12 @@ func TestTodoService_CreateTodo(t *testing.T) {
 	service := NewTodoService(mockRepo)
 	ctx := context.Background()
 
+	dueDate := time.Now().Add(24 * time.Hour)
 	request := domain.CreateTodoRequest{
 		Title:       "Test 1",
 		Description: "Description 1",
+		DueDate:     &dueDate,
+		Tags:        []string{"Infra", "infra", "personal"},
 	}
 
 	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Todo")).
@@ -129,6 +269,8 @@ func TestTodoService_CreateTodo(t *testing.T) {
 			assert.NotNil(t, todo.ID)
 			assert.Equal(t, request.Title, todo.Title)
 			assert.Equal(t, request.Description, todo.Description)
+			assert.Equal(t, request.DueDate, todo.DueDate)
+			assert.Equal(t, []string{"infra", "personal"}, todo.Tags)
 			assert.False(t, todo.Completed)
 		})
 
@@ -154,26 +296,443 @@ func TestTodoService_UpdateTodo(t *testing.T) {
 		Description: "Description 1",
 		Completed:   false,
 	}
+	title, description := "Test Updated", "Description Updated"
 	request := domain.UpdateTodoRequest{
 		ID:          testID,
-		Title:       "Test Updated",
-		Description: "Description Updated",
+		Title:       &title,
+		Description: &description,
 	}
 
-	mockRepo.On("FindByID", ctx, testID).Return(existingTodo, nil)
-	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Todo")).
+	// The read and the write it is based on happen in one unit of work
+	mockRepo.On("FindByID", ctx, testID).
+		Return(existingTodo, nil).
+		Run(func(args mock.Arguments) { assert.True(t, mockRepo.atomic) })
+	mockRepo.On("Update", ctx, testID, mock.AnythingOfType("domain.TodoPatch")).
 		Return(nil).
 		Run(func(args mock.Arguments) {
-			todo := args.Get(1).(*domain.Todo)
+			patch := args.Get(2).(domain.TodoPatch)
 
-			assert.Equal(t, request.Title, todo.Title)
-			assert.Equal(t, request.Description, todo.Description)
+			assert.True(t, mockRepo.atomic)
+			assert.Equal(t, &title, patch.Title)
+			assert.Equal(t, &description, patch.Description)
+			assert.Nil(t, patch.Priority)
+			assert.Nil(t, patch.Recurrence)
 		})
 
 	result, err := service.UpdateTodo(ctx, request)
 	assert.NoError(t, err)
-	assert.Equal(t, request.Title, result.Title)
-	assert.Equal(t, request.Description, result.Description)
+	assert.Equal(t, title, result.Title)
+	assert.Equal(t, description, result.Description)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_UpdateTodo_DueDate(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	existingDue := time.Now().Add(time.Hour)
+	newDue := existingDue.Add(24 * time.Hour)
+
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, DueDate: &existingDue}, nil)
+	mockRepo.On("Update", ctx, testID, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	// Omitting the due date keeps the current one
+	result, err := service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID})
+	assert.NoError(t, err)
+	assert.Equal(t, &existingDue, result.DueDate)
+
+	result, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, DueDate: &newDue})
+	assert.NoError(t, err)
+	assert.Equal(t, &newDue, result.DueDate)
+
+	result, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, ClearDueDate: true})
+	assert.NoError(t, err)
+	assert.Nil(t, result.DueDate)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_CreateTodo_InvalidTag(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+
+	_, err := service.CreateTodo(context.Background(), domain.CreateTodoRequest{
+		Title: "Test 1",
+		Tags:  []string{"needs review"},
+	})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_CreateTodo_Validation(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	// Every broken rule is reported, not just the first
+	_, err := service.CreateTodo(ctx, domain.CreateTodoRequest{
+		Title:       "  ",
+		Description: "bell\a",
+		Tags:        []string{strings.Repeat("x", 65)},
+		Recurrence:  "sometimes",
+	})
+	var invalid *domain.ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Equal(t, []domain.FieldError{
+		{Field: "title", Message: "is required"},
+		{Field: "description", Message: "cannot contain control characters"},
+		{Field: "tags", Message: fmt.Sprintf("%q must be at most 64 characters", strings.Repeat("x", 65))},
+		{Field: "recurrence", Message: `unrecognised recurrence "sometimes"`},
+	}, invalid.Fields)
+
+	_, err = service.CreateTodo(ctx, domain.CreateTodoRequest{Title: strings.Repeat("é", 256)})
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []domain.FieldError{{Field: "title", Message: "must be at most 255 characters"}}, invalid.Fields)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// Surrounding whitespace is trimmed, line breaks in descriptions are kept
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	result, err := service.CreateTodo(ctx, domain.CreateTodoRequest{
+		Title:       strings.Repeat("é", 255) + "\n",
+		Description: " first line\n\tsecond line ",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("é", 255), result.Title)
+	assert.Equal(t, "first line\n\tsecond line", result.Description)
+}
+
+func TestTodoService_UpdateTodo_KeepsOmittedText(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, Title: "Title", Description: "Description"}, nil)
+	mockRepo.On("Update", ctx, testID, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	priority := domain.PriorityHigh
+	result, err := service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, Priority: &priority})
+	assert.NoError(t, err)
+	assert.Equal(t, "Title", result.Title)
+	assert.Equal(t, "Description", result.Description)
+
+	// A blank title is not the same as no title
+	blank := " "
+	_, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, Title: &blank})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	mockRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestTodoService_UpdateTodo_Version(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, Title: "Title", Version: 4}, nil)
+	mockRepo.On("Update", ctx, testID, mock.MatchedBy(func(patch domain.TodoPatch) bool {
+		return patch.Version == 4
+	})).Return(nil).Once()
+
+	// The patch is based on the version that was read
+	title := "New title"
+	result, err := service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, Title: &title})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.Version)
+
+	// An update that changes nothing writes nothing: Update is expected once
+	_, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, Title: &title})
+	assert.NoError(t, err)
+	assert.Len(t, mockRepo.events, 1)
+
+	// A version the caller saw earlier takes its place
+	mockRepo.On("Update", ctx, testID, mock.MatchedBy(func(patch domain.TodoPatch) bool {
+		return patch.Version == 2
+	})).Return(fmt.Errorf("todo %s: %w", testID, domain.ErrStale)).Once()
+
+	_, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, Version: 2})
+	assert.ErrorIs(t, err, domain.ErrStale)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_UpdateTodo_Description(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, Title: "Title", Description: "First line"}, nil)
+	mockRepo.On("Update", ctx, testID, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	// Appended text goes on a new line
+	extra := " Second line "
+	result, err := service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, AppendDescription: &extra})
+	assert.NoError(t, err)
+	assert.Equal(t, "First line\nSecond line", result.Description)
+
+	// The combined description must still fit
+	long := strings.Repeat("x", 9990)
+	_, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, AppendDescription: &long})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	result, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, ClearDescription: true})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Description)
+
+	mockRepo.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestTodoService_History(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := domain.WithActor(context.Background(), "ana")
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, Title: "Title", Priority: domain.PriorityLow, Version: 1}, nil)
+	mockRepo.On("Update", ctx, testID, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+	mockRepo.On("SetCompleted", ctx, testID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+	mockRepo.On("Delete", ctx, testID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Each change is recorded with only the fields it touched
+	title := "New title"
+	_, err := service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: testID, Title: &title})
+	require.NoError(t, err)
+	_, err = service.CompleteTodo(ctx, testID, domain.ToggleOptions{})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTodo(ctx, testID))
+
+	require.Len(t, mockRepo.events, 3)
+	updated, old := mockRepo.events[0], "Title"
+	assert.Equal(t, domain.EventUpdated, updated.Action)
+	assert.Equal(t, testID, updated.TodoID)
+	assert.Equal(t, "ana", updated.Actor)
+	assert.Equal(t, []domain.FieldChange{{Field: "title", Old: &old, New: &title}}, updated.Changes)
+
+	completed := "true"
+	assert.Equal(t, domain.EventCompleted, mockRepo.events[1].Action)
+	assert.Equal(t, []domain.FieldChange{{Field: "completed", New: &completed}}, mockRepo.events[1].Changes)
+
+	assert.Equal(t, domain.EventDeleted, mockRepo.events[2].Action)
+	assert.Equal(t, testID, mockRepo.events[2].TodoID)
+	assert.Empty(t, mockRepo.events[2].Changes)
+}
+
+func TestTodoService_Undo(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := domain.WithActor(context.Background(), "ana")
+
+	_, err := service.FindUndoable(ctx, 0)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	todoID, createdID := uuid.New(), uuid.New()
+	old, title, completed := "Title", "New title", "true"
+	operation := &domain.Operation{ID: uuid.New(), Events: []*domain.TodoEvent{
+		{TodoID: todoID, Action: domain.EventUpdated, Changes: []domain.FieldChange{{Field: "title", Old: &old, New: &title}}},
+		{TodoID: todoID, Action: domain.EventCompleted, Changes: []domain.FieldChange{{Field: "completed", New: &completed}}},
+		{TodoID: createdID, Action: domain.EventCreated, Changes: []domain.FieldChange{{Field: "title", New: &title}}},
+	}}
+	mockRepo.On("FindUndoable", ctx, "ana", 1).Return([]*domain.Operation{operation}, nil)
+
+	operations, err := service.FindUndoable(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []*domain.Operation{operation}, operations)
+
+	// The events are reverted newest first: the todo created last goes, then
+	// the todo is reopened, then its title is put back
+	current := &domain.Todo{ID: todoID, Title: title, Completed: true, Version: 3}
+	mockRepo.On("Delete", ctx, createdID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockRepo.On("FindByID", ctx, todoID).Return(current, nil)
+	mockRepo.On("SetCompleted", ctx, todoID, false, mock.MatchedBy(func(patch domain.TodoPatch) bool {
+		return patch.Title == nil && patch.Version == 3
+	})).Return(nil).Once()
+	mockRepo.On("Update", ctx, todoID, mock.MatchedBy(func(patch domain.TodoPatch) bool {
+		return patch.Title != nil && *patch.Title == old && patch.Version == 3
+	})).Return(nil).Once()
+	mockRepo.On("SetUndone", ctx, operation.ID, mock.AnythingOfType("*time.Time")).Return(nil).Once()
+
+	require.NoError(t, service.Undo(ctx, operations))
+	assert.Empty(t, mockRepo.events, "undoing writes no history of its own")
+	mockRepo.AssertExpectations(t)
+
+	// A todo changed since the operation is left alone
+	conflict := new(MockTodoRepository)
+	service = NewTodoService(conflict)
+	conflict.On("FindByID", ctx, todoID).Return(&domain.Todo{ID: todoID, Title: "Edited", Version: 4}, nil)
+
+	err = service.Undo(ctx, []*domain.Operation{{ID: operation.ID, Events: operation.Events[:1]}})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	conflict.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	conflict.AssertNotCalled(t, "SetUndone", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoService_Undo_Deleted(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := domain.WithActor(context.Background(), "ana")
+
+	parentID := uuid.New()
+	deletedAt := time.Now()
+	subtask := &domain.Todo{ID: uuid.New(), Title: "Subtask", ParentID: &parentID, DeletedAt: &deletedAt}
+	operation := &domain.Operation{ID: uuid.New(), Events: []*domain.TodoEvent{
+		{TodoID: subtask.ID, Action: domain.EventDeleted},
+	}}
+
+	mockRepo.On("FindAll", ctx, domain.TodoQuery{Trashed: true, IDs: []uuid.UUID{subtask.ID}}).Return([]*domain.Todo{subtask}, nil)
+	mockRepo.On("FindByID", ctx, parentID).Return(nil, fmt.Errorf("todo %s: %w", parentID, domain.ErrNotFound)).Once()
+
+	// Undoing the deletion of a subtask waits for its parent like restoring it
+	err := service.Undo(ctx, []*domain.Operation{operation})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "SetUndone", mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.On("FindByID", ctx, parentID).Return(&domain.Todo{ID: parentID}, nil).Once()
+	mockRepo.On("Restore", ctx, subtask.ID).Return(nil).Once()
+	mockRepo.On("SetUndone", ctx, operation.ID, mock.AnythingOfType("*time.Time")).Return(nil).Once()
+
+	require.NoError(t, service.Undo(ctx, []*domain.Operation{operation}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_Undo_Related(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	// Tags, blockers and archiving are written outside the todo patch
+	todoID, blockerID := uuid.New(), uuid.New()
+	oldTags, newTags, blockers, archived := "infra", "bug,infra", blockerID.String(), "true"
+	operation := &domain.Operation{ID: uuid.New(), Events: []*domain.TodoEvent{
+		{TodoID: todoID, Action: domain.EventUpdated, Changes: []domain.FieldChange{{Field: "tags", Old: &oldTags, New: &newTags}}},
+		{TodoID: todoID, Action: domain.EventUpdated, Changes: []domain.FieldChange{{Field: "blocked_by", New: &blockers}}},
+		{TodoID: todoID, Action: domain.EventUpdated, Changes: []domain.FieldChange{{Field: "archived", New: &archived}}},
+	}}
+
+	now := time.Now()
+	mockRepo.On("FindByID", ctx, todoID).Return(&domain.Todo{ID: todoID, Tags: []string{"bug", "infra"}, ArchivedAt: &now, Version: 2}, nil)
+	mockRepo.On("FindAll", ctx, domain.TodoQuery{Blocking: &todoID, IncludeArchived: true}).Return([]*domain.Todo{{ID: blockerID}}, nil)
+	mockRepo.On("Unarchive", ctx, todoID).Return(nil).Once()
+	mockRepo.On("RemoveBlocker", ctx, todoID, blockerID).Return(nil).Once()
+	mockRepo.On("RemoveTags", ctx, todoID, []string{"bug"}).Return(nil).Once()
+	mockRepo.On("SetUndone", ctx, operation.ID, mock.AnythingOfType("*time.Time")).Return(nil).Once()
+
+	require.NoError(t, service.Undo(ctx, []*domain.Operation{operation}))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_BulkUpdate(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	pending := &domain.Todo{ID: uuid.New(), Title: "Pending", Tags: []string{"sprint-12"}, Version: 2}
+	done := &domain.Todo{ID: uuid.New(), Title: "Done", Completed: true, Tags: []string{"sprint-12"}, Version: 5}
+	query := domain.TodoQuery{Tags: []string{"sprint-12"}}
+	mockRepo.On("FindAll", ctx, query).Return([]*domain.Todo{pending, done}, nil)
+
+	// A dry run reports the todos it would change and writes nothing
+	result, err := service.BulkUpdate(ctx, domain.BulkRequest{Action: domain.BulkComplete, Query: query, DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Matched)
+	require.Len(t, result.Changed, 1, "the completed todo is left as it is")
+	assert.Equal(t, pending.ID, result.Changed[0].ID)
+	assert.True(t, result.Changed[0].Completed)
+	assert.False(t, pending.Completed)
+	mockRepo.AssertNotCalled(t, "SetCompletedMany", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, mockRepo.events)
+
+	// A toggle completes and reopens in one batch each
+	mockRepo.On("SetCompletedMany", ctx, []*domain.Todo{pending}, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil).Once()
+	mockRepo.On("SetCompletedMany", ctx, []*domain.Todo{done}, false, mock.AnythingOfType("domain.TodoPatch")).Return(nil).Once()
+
+	result, err = service.BulkUpdate(ctx, domain.BulkRequest{Action: domain.BulkToggle, Query: query})
+	require.NoError(t, err)
+	require.Len(t, result.Changed, 2)
+	assert.True(t, result.Changed[0].Completed)
+	assert.False(t, result.Changed[1].Completed)
+	require.Len(t, mockRepo.events, 2)
+	assert.Equal(t, domain.EventCompleted, mockRepo.events[0].Action)
+	assert.Equal(t, domain.EventReopened, mockRepo.events[1].Action)
+	mockRepo.AssertExpectations(t)
+
+	// Every ID has to exist, or nothing is changed
+	missing := uuid.New()
+	byID := domain.TodoQuery{IDs: []uuid.UUID{pending.ID, missing}, IncludeArchived: true}
+	mockRepo.On("FindAll", ctx, byID).Return([]*domain.Todo{pending}, nil)
+
+	_, err = service.BulkUpdate(ctx, domain.BulkRequest{Action: domain.BulkDelete, IDs: byID.IDs})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	mockRepo.AssertNotCalled(t, "DeleteMany", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = service.BulkUpdate(ctx, domain.BulkRequest{Action: domain.BulkTag, Query: query, Tags: []string{" "}})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestTodoService_BulkUpdate_DeleteNested(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	// The grandchild goes to the trash with the parent, through a subtask
+	// the filter did not select
+	parent := &domain.Todo{ID: uuid.New(), Title: "Parent", Version: 1}
+	subtask := &domain.Todo{ID: uuid.New(), Title: "Subtask", ParentID: &parent.ID, Version: 1}
+	grandchild := &domain.Todo{ID: uuid.New(), Title: "Grandchild", ParentID: &subtask.ID, Version: 1}
+	query := domain.TodoQuery{Tags: []string{"x"}}
+	mockRepo.On("FindAll", ctx, query).Return([]*domain.Todo{parent, grandchild}, nil)
+	mockRepo.On("FindByID", ctx, subtask.ID).Return(subtask, nil)
+	mockRepo.On("DeleteMany", ctx, []*domain.Todo{parent, grandchild}, mock.AnythingOfType("time.Time")).Return(nil)
+
+	result, err := service.BulkUpdate(ctx, domain.BulkRequest{Action: domain.BulkDelete, Query: query})
+	require.NoError(t, err)
+	assert.Len(t, result.Changed, 2)
+
+	// Only the parent's deletion is recorded, so undo restores it once
+	require.Len(t, mockRepo.events, 1)
+	assert.Equal(t, domain.EventDeleted, mockRepo.events[0].Action)
+	assert.Equal(t, parent.ID, mockRepo.events[0].TodoID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_AddTags(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	tagged := &domain.Todo{ID: testID, Tags: []string{"bug", "infra"}}
+	before, after := "infra", "bug,infra"
+
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, Tags: []string{"infra"}}, nil).Once()
+	mockRepo.On("AddTags", ctx, testID, []string{"bug", "infra"}).Return(nil).Once()
+	mockRepo.On("FindByID", ctx, testID).Return(tagged, nil)
+
+	// Tags are lower-cased and de-duplicated
+	result, err := service.AddTags(ctx, testID, []string{"Bug", " infra ", "bug"})
+	assert.NoError(t, err)
+	assert.Equal(t, tagged.Tags, result.Tags)
+
+	// The change is recorded like any other update
+	require.Len(t, mockRepo.events, 1)
+	assert.Equal(t, domain.EventUpdated, mockRepo.events[0].Action)
+	assert.Equal(t, []domain.FieldChange{{Field: "tags", Old: &before, New: &after}}, mockRepo.events[0].Changes)
+
+	// Adding tags the todo already has writes nothing
+	_, err = service.AddTags(ctx, testID, []string{"bug"})
+	assert.NoError(t, err)
+	assert.Len(t, mockRepo.events, 1)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -185,7 +744,7 @@ func TestTodoService_DeleteTodo(t *testing.T) {
 
 	testID := uuid.New()
 
-	mockRepo.On("Delete", ctx, testID).Return(nil)
+	mockRepo.On("Delete", ctx, testID, mock.AnythingOfType("time.Time")).Return(nil)
 
 	err := service.DeleteTodo(ctx, testID)
 	assert.NoError(t, err)
@@ -193,6 +752,35 @@ func TestTodoService_DeleteTodo(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestTodoService_RestoreTodo(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	parentID := uuid.New()
+	deletedAt := time.Now()
+	subtask := &domain.Todo{ID: uuid.New(), Title: "Subtask", ParentID: &parentID, DeletedAt: &deletedAt}
+
+	mockRepo.On("FindAll", ctx, domain.TodoQuery{Trashed: true, IDs: []uuid.UUID{subtask.ID}}).Return([]*domain.Todo{subtask}, nil)
+	mockRepo.On("FindByID", ctx, parentID).Return(nil, fmt.Errorf("todo %s: %w", parentID, domain.ErrNotFound)).Once()
+
+	// A subtask waits for its parent to be restored
+	_, err := service.RestoreTodo(ctx, subtask.ID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+
+	restored := &domain.Todo{ID: subtask.ID, Title: "Subtask", ParentID: &parentID}
+	mockRepo.On("FindByID", ctx, parentID).Return(&domain.Todo{ID: parentID}, nil).Once()
+	mockRepo.On("Restore", ctx, subtask.ID).Return(nil)
+	mockRepo.On("FindByID", ctx, subtask.ID).Return(restored, nil)
+
+	result, err := service.RestoreTodo(ctx, subtask.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, result.DeletedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestTodoServiceImpl_ToggleTodo(t *testing.T) {
 	mockRepo := new(MockTodoRepository)
 	service := NewTodoService(mockRepo)
@@ -207,19 +795,368 @@ func TestTodoServiceImpl_ToggleTodo(t *testing.T) {
 	}
 
 	mockRepo.On("FindByID", ctx, testID).Return(existingTodo, nil)
-	mockRepo.On("Update", ctx, existingTodo).
+	mockRepo.On("SetCompleted", ctx, testID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil).Once()
+
+	result, err := service.ToggleTodo(ctx, testID, domain.ToggleOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, existingTodo.ID, result.ID)
+	assert.True(t, result.Completed)
+	assert.NotNil(t, result.CompletedAt)
+
+	// Toggling again reopens it
+	mockRepo.On("SetCompleted", ctx, testID, false, mock.AnythingOfType("domain.TodoPatch")).Return(nil).Once()
+
+	result, err = service.ToggleTodo(ctx, testID, domain.ToggleOptions{})
+	assert.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Nil(t, result.CompletedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_CompleteTodo_Idempotent(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	doneAt := time.Now().Add(-time.Hour)
+	done := &domain.Todo{ID: uuid.New(), Title: "Done", Completed: true, CompletedAt: &doneAt}
+	pending := &domain.Todo{ID: uuid.New(), Title: "Pending"}
+
+	mockRepo.On("FindByID", ctx, done.ID).Return(done, nil)
+	mockRepo.On("FindByID", ctx, pending.ID).Return(pending, nil)
+
+	// Completing a done todo or reopening a pending one writes nothing
+	result, err := service.CompleteTodo(ctx, done.ID, domain.ToggleOptions{})
+	assert.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.Equal(t, &doneAt, result.CompletedAt)
+
+	result, err = service.ReopenTodo(ctx, pending.ID)
+	assert.NoError(t, err)
+	assert.False(t, result.Completed)
+
+	mockRepo.AssertNotCalled(t, "SetCompleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.On("SetCompleted", ctx, done.ID, false, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	result, err = service.ReopenTodo(ctx, done.ID)
+	assert.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Nil(t, result.CompletedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_ArchiveTodo(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	archivedAt := time.Now().Add(-time.Hour)
+	archived := &domain.Todo{ID: uuid.New(), Title: "Archived", ArchivedAt: &archivedAt}
+	todo := &domain.Todo{ID: uuid.New(), Title: "Todo"}
+
+	mockRepo.On("FindByID", ctx, archived.ID).Return(archived, nil)
+
+	// Archiving an archived todo writes nothing
+	result, err := service.ArchiveTodo(ctx, archived.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, &archivedAt, result.ArchivedAt)
+	mockRepo.AssertNotCalled(t, "Archive", mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.On("FindByID", ctx, todo.ID).Return(todo, nil)
+	mockRepo.On("Archive", ctx, domain.TodoQuery{IDs: []uuid.UUID{todo.ID}}, mock.AnythingOfType("time.Time")).Return(1, nil)
+
+	_, err = service.ArchiveTodo(ctx, todo.ID)
+	assert.NoError(t, err)
+	require.Len(t, mockRepo.events, 1)
+	yes := "true"
+	assert.Equal(t, []domain.FieldChange{{Field: "archived", New: &yes}}, mockRepo.events[0].Changes)
+
+	// Bulk archiving only picks completed todos, and records each of them
+	before := time.Now().AddDate(0, 0, -14)
+	completedBefore := mock.MatchedBy(func(q domain.TodoQuery) bool {
+		return q.Completed != nil && *q.Completed && q.CompletedBefore.Equal(before)
+	})
+	mockRepo.On("FindAll", ctx, completedBefore).Return([]*domain.Todo{
+		{ID: uuid.New(), Completed: true}, {ID: uuid.New(), Completed: true}, {ID: uuid.New(), Completed: true},
+	}, nil)
+	mockRepo.On("Archive", ctx, completedBefore, mock.AnythingOfType("time.Time")).Return(3, nil)
+
+	count, err := service.ArchiveCompleted(ctx, before)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Len(t, mockRepo.events, 4)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_UpdateTodo_ParentCycle(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	rootID := uuid.New()
+	root := &domain.Todo{ID: rootID, Title: "Root"}
+	child := &domain.Todo{ID: uuid.New(), Title: "Child", ParentID: &rootID}
+
+	mockRepo.On("FindByID", ctx, rootID).Return(root, nil)
+	mockRepo.On("FindByID", ctx, child.ID).Return(child, nil)
+
+	// Moving the root under its own subtask would create a cycle
+	_, err := service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: rootID, ParentID: &child.ID})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	_, err = service.UpdateTodo(ctx, domain.UpdateTodoRequest{ID: rootID, ParentID: &rootID})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoService_ToggleTodo_Cascade(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	parentID := uuid.New()
+	parent := &domain.Todo{ID: parentID, Title: "Parent", Subtasks: domain.Progress{Done: 1, Total: 2}}
+	done := &domain.Todo{ID: uuid.New(), Title: "Done", Completed: true, ParentID: &parentID}
+	pending := &domain.Todo{ID: uuid.New(), Title: "Pending", ParentID: &parentID}
+
+	mockRepo.On("FindByID", ctx, parentID).Return(parent, nil)
+	mockRepo.On("FindAll", ctx, mock.MatchedBy(func(q domain.TodoQuery) bool {
+		return q.ParentID != nil && *q.ParentID == parentID
+	})).Return([]*domain.Todo{done, pending}, nil)
+	mockRepo.On("FindAll", ctx, mock.AnythingOfType("domain.TodoQuery")).Return([]*domain.Todo{}, nil)
+	mockRepo.On("SetCompleted", ctx, parentID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+	mockRepo.On("SetCompleted", ctx, pending.ID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	result, err := service.ToggleTodo(ctx, parentID, domain.ToggleOptions{Cascade: true})
+	assert.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.Equal(t, domain.Progress{Done: 2, Total: 2}, result.Subtasks)
+
+	// Subtasks that are already done are left alone
+	mockRepo.AssertNotCalled(t, "SetCompleted", ctx, done.ID, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_ToggleTodo_CascadeBlocked(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	parentID := uuid.New()
+	parent := &domain.Todo{ID: parentID, Title: "Parent", Subtasks: domain.Progress{Total: 1}}
+	blocked := &domain.Todo{ID: uuid.New(), Title: "Blocked", ParentID: &parentID, OpenBlockers: 1}
+
+	mockRepo.On("FindByID", ctx, parentID).Return(parent, nil)
+	mockRepo.On("FindAll", ctx, mock.MatchedBy(func(q domain.TodoQuery) bool {
+		return q.ParentID != nil && *q.ParentID == parentID
+	})).Return([]*domain.Todo{blocked}, nil)
+	mockRepo.On("FindAll", ctx, mock.AnythingOfType("domain.TodoQuery")).Return([]*domain.Todo{}, nil)
+	mockRepo.On("SetCompleted", ctx, parentID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	// A blocked subtask fails the whole toggle, as a blocked todo does
+	_, err := service.ToggleTodo(ctx, parentID, domain.ToggleOptions{Cascade: true})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	mockRepo.AssertNotCalled(t, "SetCompleted", ctx, blocked.ID, mock.Anything, mock.Anything)
+
+	parent.Completed = false
+	mockRepo.On("SetCompleted", ctx, blocked.ID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil).Once()
+
+	_, err = service.ToggleTodo(ctx, parentID, domain.ToggleOptions{Cascade: true, Force: true})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_CreateTodo_Recurrence(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Todo")).Return(nil)
+
+	// Rules are stored in RRULE form
+	result, err := service.CreateTodo(ctx, domain.CreateTodoRequest{Title: "Rotate certs", Recurrence: "every 2 weeks"})
+	assert.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2", result.Recurrence)
+
+	_, err = service.CreateTodo(ctx, domain.CreateTodoRequest{Title: "Rotate certs", Recurrence: "sometimes"})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestTodoService_ToggleTodo_Recurring(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	due := time.Now().Add(-time.Hour)
+	existingTodo := &domain.Todo{
+		ID:         testID,
+		Title:      "Review dependabot",
+		Tags:       []string{"chore"},
+		DueDate:    &due,
+		Recurrence: "FREQ=WEEKLY",
+	}
+
+	mockRepo.On("FindByID", ctx, testID).Return(existingTodo, nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Todo")).
 		Return(nil).
 		Run(func(args mock.Arguments) {
-			todo := args.Get(1).(*domain.Todo)
+			next := args.Get(1).(*domain.Todo)
 
-			assert.Equal(t, todo.ID, existingTodo.ID)
-			assert.Equal(t, todo.Completed, existingTodo.Completed)
+			assert.NotEqual(t, testID, next.ID)
+			assert.Equal(t, existingTodo.Title, next.Title)
+			assert.Equal(t, []string{"chore"}, next.Tags)
+			assert.Equal(t, "FREQ=WEEKLY", next.Recurrence)
+			assert.False(t, next.Completed)
+			assert.True(t, due.AddDate(0, 0, 7).Equal(*next.DueDate))
 		})
+	mockRepo.On("SetCompleted", ctx, testID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	result, err := service.ToggleTodo(ctx, testID, domain.ToggleOptions{})
+	assert.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.NotNil(t, result.NextOccurrence)
+
+	// The rule moves to the next occurrence
+	assert.Empty(t, result.Recurrence)
 
-	result, err := service.ToggleTodo(ctx, testID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_ToggleTodo_CascadeRecurring(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	parentID := uuid.New()
+	parent := &domain.Todo{ID: parentID, Title: "Release", Subtasks: domain.Progress{Total: 1}}
+	subtask := &domain.Todo{ID: uuid.New(), Title: "Rotate certs", ParentID: &parentID, Recurrence: "FREQ=WEEKLY"}
+
+	mockRepo.On("FindByID", ctx, parentID).Return(parent, nil)
+	mockRepo.On("FindAll", ctx, mock.MatchedBy(func(q domain.TodoQuery) bool {
+		return q.ParentID != nil && *q.ParentID == parentID
+	})).Return([]*domain.Todo{subtask}, nil)
+	mockRepo.On("FindAll", ctx, mock.AnythingOfType("domain.TodoQuery")).Return([]*domain.Todo{}, nil)
+	mockRepo.On("SetCompleted", ctx, parentID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+	mockRepo.On("SetCompleted", ctx, subtask.ID, true, mock.MatchedBy(func(patch domain.TodoPatch) bool {
+		return patch.Recurrence != nil && *patch.Recurrence == ""
+	})).Return(nil).Once()
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(next *domain.Todo) bool {
+		return next.Recurrence == "FREQ=WEEKLY" && *next.ParentID == parentID
+	})).Return(nil).Once()
+
+	// A recurring subtask completed by the cascade recurs as it would on its own
+	result, err := service.ToggleTodo(ctx, parentID, domain.ToggleOptions{Cascade: true})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Progress{Done: 1, Total: 2}, result.Subtasks)
+	require.Len(t, mockRepo.events, 3)
+	assert.Equal(t, domain.EventCreated, mockRepo.events[1].Action, "the next occurrence is in the history")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTodoService_BlockTodo_Cycle(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	a := &domain.Todo{ID: uuid.New(), Title: "A"}
+	b := &domain.Todo{ID: uuid.New(), Title: "B"}
+	c := &domain.Todo{ID: uuid.New(), Title: "C"}
+
+	// A is blocked by B, which is blocked by C
+	mockRepo.On("FindByID", ctx, c.ID).Return(c, nil)
+	mockRepo.On("FindByID", ctx, a.ID).Return(a, nil)
+	mockRepo.On("FindAll", ctx, domain.TodoQuery{Blocking: &a.ID, IncludeArchived: true}).Return([]*domain.Todo{b}, nil)
+	mockRepo.On("FindAll", ctx, domain.TodoQuery{Blocking: &b.ID, IncludeArchived: true}).Return([]*domain.Todo{c}, nil)
+
+	_, err := service.BlockTodo(ctx, c.ID, a.ID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	_, err = service.BlockTodo(ctx, a.ID, a.ID)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	mockRepo.AssertNotCalled(t, "AddBlocker", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTodoService_ToggleTodo_Blocked(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	testID := uuid.New()
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, OpenBlockers: 1}, nil).Once()
+
+	_, err := service.ToggleTodo(ctx, testID, domain.ToggleOptions{})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	mockRepo.AssertNotCalled(t, "SetCompleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Forcing completes it anyway
+	mockRepo.On("FindByID", ctx, testID).Return(&domain.Todo{ID: testID, OpenBlockers: 1}, nil).Once()
+	mockRepo.On("SetCompleted", ctx, testID, true, mock.AnythingOfType("domain.TodoPatch")).Return(nil)
+
+	result, err := service.ToggleTodo(ctx, testID, domain.ToggleOptions{Force: true})
 	assert.NoError(t, err)
-	assert.Equal(t, result.ID, existingTodo.ID)
-	assert.Equal(t, result.Completed, existingTodo.Completed)
+	assert.True(t, result.Completed)
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestTodoService_FindAllTodos_InvalidPage(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+
+	_, err := service.FindAllTodos(context.Background(), domain.TodoQuery{Limit: 10, Offset: -10})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
+
+func TestTodoService_CompletionStats(t *testing.T) {
+	mockRepo := new(MockTodoRepository)
+	service := NewTodoService(mockRepo)
+	ctx := context.Background()
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -13)
+	completed := func(lead time.Duration, daysAgo int) *domain.Todo {
+		completedAt := now.AddDate(0, 0, -daysAgo)
+		return &domain.Todo{ID: uuid.New(), Completed: true, CreatedAt: completedAt.Add(-lead), CompletedAt: &completedAt}
+	}
+
+	mockRepo.On("FindAll", ctx, mock.MatchedBy(func(q domain.TodoQuery) bool {
+		return q.Completed != nil && *q.Completed && q.CompletedAfter.Equal(since)
+	})).Return([]*domain.Todo{
+		completed(time.Hour, 0),
+		completed(3*time.Hour, 0),
+		completed(48*time.Hour, 2),
+		completed(5*time.Hour, 10),
+	}, nil)
+
+	stats, err := service.CompletionStats(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Completed)
+	assert.Equal(t, domain.Duration(4*time.Hour), stats.MedianLeadTime)
+
+	// Every day of the period is listed, including those without completions
+	require.Len(t, stats.Days, 14)
+	assert.Equal(t, 2, stats.Days[13].Count)
+	assert.Equal(t, 1, stats.Days[11].Count)
+	assert.Equal(t, 1, stats.Days[3].Count)
+
+	total := 0
+	for _, week := range stats.Weeks {
+		assert.Equal(t, time.Monday, week.Start.Weekday())
+		total += week.Count
+	}
+	assert.Equal(t, 4, total)
+
+	_, err = service.CompletionStats(ctx, now.Add(time.Hour))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+)
+
+// atomically runs fn as one unit of work, handing it a copy of s whose
+// repository calls all take part in it.
+func atomically[T any](ctx context.Context, s todoServiceImpl, fn func(s todoServiceImpl) (T, error)) (T, error) {
+	return unitOfWork(ctx, s.repo, func(repo domain.TodoRepository) (T, error) {
+		return fn(todoServiceImpl{repo: repo})
+	})
+}
+
+// unitOfWork runs fn in a unit of work of uow and returns what fn returns,
+// or the zero T when fn or the commit fails.
+func unitOfWork[R, T any](ctx context.Context, uow domain.UnitOfWork[R], fn func(repo R) (T, error)) (T, error) {
+	var result T
+	err := uow.Atomically(ctx, func(repo R) error {
+		var err error
+		result, err = fn(repo)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/recurrence"
+)
+
+// textRule describes what a free-text field accepts. Lengths follow the
+// column sizes in the schema and count characters, not bytes.
+type textRule struct {
+	required  bool
+	maxLength int
+	// multiline admits line breaks and tabs; other control characters are
+	// always rejected
+	multiline bool
+}
+
+var (
+	titleRule              = textRule{required: true, maxLength: 255}
+	descriptionRule        = textRule{maxLength: 10000, multiline: true}
+	projectNameRule        = textRule{required: true, maxLength: 100}
+	projectDescriptionRule = textRule{maxLength: 10000, multiline: true}
+)
+
+const maxTagLength = 64
+
+// validator collects the rules a request breaks so that all of them are
+// reported at once rather than one per attempt.
+type validator struct {
+	fields []domain.FieldError
+}
+
+func (v *validator) fail(field, format string, args ...any) {
+	v.fields = append(v.fields, domain.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns a *domain.ValidationError listing every failure, or nil.
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &domain.ValidationError{Fields: v.fields}
+}
+
+// text trims surrounding whitespace off value, checks what is left against
+// rule and returns it.
+func (v *validator) text(field, value string, rule textRule) string {
+	value = strings.TrimSpace(value)
+
+	switch {
+	case value == "":
+		if rule.required {
+			v.fail(field, "is required")
+		}
+	case !utf8.ValidString(value):
+		v.fail(field, "must be valid UTF-8")
+	case utf8.RuneCountInString(value) > rule.maxLength:
+		v.fail(field, "must be at most %d characters", rule.maxLength)
+	case hasControlCharacters(value, rule.multiline):
+		v.fail(field, "cannot contain control characters")
+	}
+
+	return value
+}
+
+// tags lower-cases and de-duplicates tags. Tags are single words, so
+// whitespace and commas are rejected.
+func (v *validator) tags(field string, tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		switch {
+		case strings.ContainsAny(tag, ", \t\n"):
+			v.fail(field, "%q cannot contain spaces or commas", tag)
+		case utf8.RuneCountInString(tag) > maxTagLength:
+			v.fail(field, "%q must be at most %d characters", tag, maxTagLength)
+		case hasControlCharacters(tag, false):
+			v.fail(field, "%q cannot contain control characters", tag)
+		default:
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
+
+// recurrence validates a recurrence rule and returns it in RRULE form. An
+// empty rule means the todo does not repeat.
+func (v *validator) recurrence(field, rule string) string {
+	if strings.TrimSpace(rule) == "" {
+		return ""
+	}
+
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		v.fail(field, "%v", err)
+		return ""
+	}
+	return parsed.String()
+}
+
+func (v *validator) priority(field string, priority domain.Priority) {
+	if priority < domain.PriorityNone || priority > domain.PriorityCritical {
+		v.fail(field, "must be none, low, medium, high or critical")
+	}
+}
+
+func hasControlCharacters(s string, multiline bool) bool {
+	for _, r := range s {
+		if multiline && (r == '\n' || r == '\r' || r == '\t') {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/migrate"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/repository"
+	"github.com/leandrowiemesfilho/go-todo-cli/migrations"
+)
+
+func init() {
+	Register("postgres", openPostgres)
+	Register("postgresql", openPostgres)
+}
+
+func openPostgres(ctx context.Context, dsn *url.URL) (*Backend, error) {
+	schema, err := migrate.Load(migrations.Postgres, "postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	dbPool, err := createDBPool(ctx, dsn.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{
+		Repository:        repository.NewTodoRepository(dbPool),
+		ProjectRepository: repository.NewProjectRepository(dbPool),
+		Migrator:          migrate.New(migrate.NewPostgresDriver(dbPool), schema),
+		ping:              dbPool.Ping,
+		close:             dbPool.Close,
+	}, nil
+}
+
+func createDBPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection string: %v", err)
+	}
+
+	// Set connection pool settings
+	poolConfig.MaxConns = 10
+	poolConfig.MinConns = 2
+	poolConfig.MaxConnLifetime = time.Hour
+	poolConfig.MaxConnIdleTime = 30 * time.Minute
+
+	dbPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %v", err)
+	}
+
+	// Test the connection
+	if err := dbPool.Ping(ctx); err != nil {
+		dbPool.Close()
+		return nil, fmt.Errorf("unable to ping database: %v", err)
+	}
+
+	// Keep stdout clean for machine-readable output
+	fmt.Fprintln(os.Stderr, "✅ Successfully connected to PostgreSQL database")
+	return dbPool, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/migrate"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/repository"
+	"github.com/leandrowiemesfilho/go-todo-cli/migrations"
+)
+
+func init() {
+	Register("sqlite", openSQLite)
+	Register("file", openSQLite)
+	Register("memory", openSQLite)
+}
+
+func openSQLite(ctx context.Context, dsn *url.URL) (*Backend, error) {
+	path, err := sqlitePath(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := migrate.Load(migrations.SQLite, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := repository.OpenSQLite(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{
+		Repository:        repository.NewSQLiteTodoRepository(db),
+		ProjectRepository: repository.NewSQLiteProjectRepository(db),
+		Migrator:          migrate.New(migrate.NewSQLiteDriver(db), schema),
+		ping:              db.PingContext,
+		close:             func() { db.Close() },
+	}, nil
+}
+
+// sqlitePath maps sqlite://todo.db, sqlite:///abs/todo.db, file:todo.db and
+// memory:// onto a path the driver understands.
+func sqlitePath(dsn *url.URL) (string, error) {
+	if dsn.Scheme == "memory" {
+		return ":memory:", nil
+	}
+
+	path := dsn.Opaque
+	if path == "" {
+		path = dsn.Host + dsn.Path
+	}
+	if path == "" {
+		return "", fmt.Errorf("missing database path in %q", dsn.String())
+	}
+
+	return path, nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/migrate"
+)
+
+// Backend is an opened storage backend together with the connection handle
+// behind its repositories. Open does not touch the schema; run Migrator.Up
+// before using the repositories on a fresh database.
+type Backend struct {
+	Repository        domain.TodoRepository
+	ProjectRepository domain.ProjectRepository
+	Migrator          *migrate.Migrator
+	ping              func(ctx context.Context) error
+	close             func()
+}
+
+func (b *Backend) Ping(ctx context.Context) error {
+	return b.ping(ctx)
+}
+
+func (b *Backend) Close() {
+	b.close()
+}
+
+// OpenFunc opens the backend addressed by dsn.
+type OpenFunc func(ctx context.Context, dsn *url.URL) (*Backend, error)
+
+var registry = map[string]OpenFunc{}
+
+// Register makes a backend available for the given URL scheme. It panics if
+// the scheme is registered twice.
+func Register(scheme string, open OpenFunc) {
+	if _, ok := registry[scheme]; ok {
+		panic("storage: backend registered twice for scheme " + scheme)
+	}
+	registry[scheme] = open
+}
+
+// Open selects a backend by the scheme of dsn, e.g. postgres://, sqlite://,
+// file: or memory://.
+func Open(ctx context.Context, dsn string) (*Backend, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend URL: %v", err)
+	}
+
+	open, ok := registry[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q (supported: %s)", u.Scheme, strings.Join(Schemes(), ", "))
+	}
+
+	return open(ctx, u)
+}
+
+// Schemes lists the registered URL schemes.
+func Schemes() []string {
+	schemes := make([]string, 0, len(registry))
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
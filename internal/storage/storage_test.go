@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/leandrowiemesfilho/go-todo-cli/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLitePath(t *testing.T) {
+	tests := map[string]string{
+		"sqlite://todo.db":          "todo.db",
+		"sqlite:///var/lib/todo.db": "/var/lib/todo.db",
+		"file:todo.db":              "todo.db",
+		"file:///tmp/todo.db":       "/tmp/todo.db",
+		"memory://":                 ":memory:",
+	}
+
+	for dsn, expected := range tests {
+		u, err := url.Parse(dsn)
+		assert.NoError(t, err)
+
+		path, err := sqlitePath(u)
+		assert.NoError(t, err, dsn)
+		assert.Equal(t, expected, path, dsn)
+	}
+}
+
+func TestSQLitePath_Missing(t *testing.T) {
+	u, _ := url.Parse("sqlite://")
+	_, err := sqlitePath(u)
+	assert.Error(t, err)
+}
+
+func TestOpen_Memory(t *testing.T) {
+	ctx := context.Background()
+
+	backend, err := Open(ctx, "memory://")
+	assert.NoError(t, err)
+	defer backend.Close()
+
+	assert.NoError(t, backend.Ping(ctx))
+
+	_, err = backend.Migrator.Up(ctx)
+	assert.NoError(t, err)
+
+	todos, err := backend.Repository.FindAll(ctx, domain.TodoQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, todos)
+}
+
+func TestOpen_UnknownScheme(t *testing.T) {
+	_, err := Open(context.Background(), "mysql://localhost/todo")
+	assert.ErrorContains(t, err, "unknown backend")
+}
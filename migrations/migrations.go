@@ -0,0 +1,13 @@
+package migrations
+
+import "embed"
+
+// Postgres and SQLite hold the versioned schema migrations for each backend.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+var (
+	//go:embed postgres/*.sql
+	Postgres embed.FS
+
+	//go:embed sqlite/*.sql
+	SQLite embed.FS
+)
@@ -0,0 +1,2 @@
+-- Drop todos table
+DROP TABLE IF EXISTS todos;
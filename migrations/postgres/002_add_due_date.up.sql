@@ -0,0 +1,4 @@
+-- Add optional due date to todos
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_date TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_todos_due_date ON todos(due_date);
@@ -0,0 +1,4 @@
+-- Let todos be broken down into subtasks
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES todos(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos(parent_id);
@@ -0,0 +1,9 @@
+-- Record which todos have to be done before another can be completed
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    blocker_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, blocker_id),
+    CHECK (todo_id <> blocker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocker_id ON todo_dependencies(blocker_id);
@@ -0,0 +1,2 @@
+-- Count the writes to each todo so concurrent updates can detect each other
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+-- Record when a todo was completed; todos done before this use their last update
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE todos SET completed_at = updated_at WHERE completed AND completed_at IS NULL;
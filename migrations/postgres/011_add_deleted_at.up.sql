@@ -0,0 +1,4 @@
+-- Move deleted todos to the trash instead of removing them right away
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos(deleted_at);
@@ -0,0 +1,11 @@
+-- Record every change to a todo, with who made it and when
+CREATE TABLE IF NOT EXISTS todo_events (
+    id BIGSERIAL PRIMARY KEY,
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    actor VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_events_todo_id ON todo_events(todo_id, id);
@@ -0,0 +1,10 @@
+-- Group the events of one command into an operation that can be undone and redone;
+-- events recorded before this each become an operation of their own
+ALTER TABLE todo_events ADD COLUMN IF NOT EXISTS operation UUID;
+ALTER TABLE todo_events ADD COLUMN IF NOT EXISTS undone_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE todo_events SET operation = gen_random_uuid() WHERE operation IS NULL;
+ALTER TABLE todo_events ALTER COLUMN operation SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todo_events_actor ON todo_events(actor, id);
+CREATE INDEX IF NOT EXISTS idx_todo_events_operation ON todo_events(operation);
@@ -0,0 +1,4 @@
+-- Add priority to todos (0 = none ... 4 = critical)
+ALTER TABLE todos ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_todos_priority ON todos(priority);
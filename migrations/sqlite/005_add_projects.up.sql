@@ -0,0 +1,15 @@
+-- Create projects and assign todos to them
+CREATE TABLE IF NOT EXISTS projects (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+
+-- SQLite cannot drop a column that carries a foreign key, so project_id is a
+-- plain column here. Projects are archived rather than deleted.
+ALTER TABLE todos ADD COLUMN project_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos(project_id);
@@ -0,0 +1,7 @@
+-- Let todos be broken down into subtasks
+
+-- SQLite cannot drop a column that carries a foreign key, so parent_id is a
+-- plain column here and the repository deletes subtasks itself.
+ALTER TABLE todos ADD COLUMN parent_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos(parent_id);
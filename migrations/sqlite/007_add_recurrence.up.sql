@@ -0,0 +1,2 @@
+-- Store the RRULE a todo repeats on; empty means it does not repeat
+ALTER TABLE todos ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '';
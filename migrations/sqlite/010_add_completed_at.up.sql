@@ -0,0 +1,4 @@
+-- Record when a todo was completed; todos done before this use their last update
+ALTER TABLE todos ADD COLUMN completed_at TEXT;
+
+UPDATE todos SET completed_at = updated_at WHERE completed;
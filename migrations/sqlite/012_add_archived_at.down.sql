@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todos_active;
+
+ALTER TABLE todos DROP COLUMN archived_at;
@@ -0,0 +1,5 @@
+-- Archive old todos so they drop out of the default listing
+ALTER TABLE todos ADD COLUMN archived_at TEXT;
+
+-- Default queries only look at live, unarchived todos
+CREATE INDEX IF NOT EXISTS idx_todos_active ON todos(created_at DESC) WHERE archived_at IS NULL AND deleted_at IS NULL;
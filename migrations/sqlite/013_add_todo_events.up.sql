@@ -0,0 +1,11 @@
+-- Record every change to a todo, with who made it and when
+CREATE TABLE IF NOT EXISTS todo_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    changes TEXT NOT NULL DEFAULT '[]',
+    actor TEXT NOT NULL,
+    occurred_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_events_todo_id ON todo_events(todo_id, id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todo_events_operation;
+DROP INDEX IF EXISTS idx_todo_events_actor;
+
+ALTER TABLE todo_events DROP COLUMN undone_at;
+ALTER TABLE todo_events DROP COLUMN operation;
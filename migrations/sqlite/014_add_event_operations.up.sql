@@ -0,0 +1,14 @@
+-- Group the events of one command into an operation that can be undone and redone;
+-- events recorded before this each become an operation of their own
+ALTER TABLE todo_events ADD COLUMN operation TEXT NOT NULL DEFAULT '';
+ALTER TABLE todo_events ADD COLUMN undone_at TEXT;
+
+UPDATE todo_events
+SET operation = lower(
+    hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' ||
+    hex(randomblob(2)) || '-' || hex(randomblob(6))
+)
+WHERE operation = '';
+
+CREATE INDEX IF NOT EXISTS idx_todo_events_actor ON todo_events(actor, id);
+CREATE INDEX IF NOT EXISTS idx_todo_events_operation ON todo_events(operation);